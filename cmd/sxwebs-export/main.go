@@ -0,0 +1,194 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Sxwebs-export writes a site, whose pages are Sx procedures, as static
+// HTML files into a directory.
+//
+// Usage:
+//
+//	sxwebs-export -site site.sxn [-base /docs/] [-out public] [-assets static] [-check] file.sx...
+//
+// The site file describes the nodes of the site, which is created by
+// webs/site.New. The pattern of a node is relative to the base path:
+//
+//	(site "Documentation"
+//	  (node "home" "Home" ""
+//	    (node "intro" "Introduction" "intro/")
+//	    (node "imprint" "Imprint" "imprint/" :invisible)))
+//
+// The Sx files are evaluated in order, with all builtins of package sxsite
+// available. They bind the page procedure of a node "intro" to the symbol
+// "page:intro". A page procedure (lambda (node-id) ...) returns the SxHTML
+// of the page. Nodes without a page procedure and nodes with path parameters
+// are not exported.
+//
+// All files of the assets directory are copied into the output directory.
+// Existing files are overwritten.
+//
+// With -check, nothing is written. Instead, all calls of "url-for" and
+// "make-url" in the Sx files and all links of the rendered pages are checked.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxsite"
+	"t73f.de/r/webs/site"
+)
+
+func main() {
+	siteFile := flag.String("site", "", "file with the site description")
+	base := flag.String("base", "/", "base path of the site")
+	outDir := flag.String("out", "public", "output directory")
+	assetsDir := flag.String("assets", "", "directory of static assets")
+	check := flag.Bool("check", false, "only check the links of the site")
+	flag.Parse()
+	if *siteFile == "" {
+		fmt.Fprintln(os.Stderr, "sxwebs-export: missing -site")
+		flag.Usage()
+		os.Exit(2)
+	}
+	st, err := readSite(*siteFile, *base)
+	if err == nil {
+		err = run(st, *outDir, *assetsDir, *check, flag.Args())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sxwebs-export:", err)
+		os.Exit(1)
+	}
+}
+
+// readSite reads the site description of the file.
+func readSite(siteFile, base string) (*site.Site, error) {
+	spec, err := readFile(siteFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec) != 1 {
+		return nil, fmt.Errorf("%s: expected one site description, but got %d", siteFile, len(spec))
+	}
+	st, err := buildSite(spec[0], base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", siteFile, err)
+	}
+	return st, nil
+}
+
+func run(st *site.Site, outDir, assetsDir string, check bool, sxFiles []string) error {
+	bind := sxeval.MakeRootBinding(256)
+	if err := sxbuiltins.BindAll(bind); err != nil {
+		return err
+	}
+	if err := sxsite.NewBinder(st).Bind(bind); err != nil {
+		return err
+	}
+	env := sxeval.MakeEnvironment(bind)
+	var source []sx.Object
+	for _, sxFile := range sxFiles {
+		objs, errRead := readFile(sxFile)
+		if errRead != nil {
+			return errRead
+		}
+		for _, obj := range objs {
+			if _, err := env.Eval(obj); err != nil {
+				return fmt.Errorf("%s: %w", sxFile, err)
+			}
+		}
+		source = append(source, objs...)
+	}
+
+	render := func(node *site.Node) (*sx.Pair, error) {
+		obj, found := bind.Lookup(sx.MakeSymbol(sxsite.PagePrefix + node.ID()))
+		if !found {
+			return nil, nil
+		}
+		proc, isCallable := sxeval.GetCallable(obj)
+		if !isCallable {
+			return nil, fmt.Errorf("page is not a procedure, but %T/%v", obj, obj)
+		}
+		res, errCall := env.Call(proc, sx.Vector{sx.MakeString(node.ID())})
+		if errCall != nil {
+			return nil, errCall
+		}
+		page, isPair := sx.GetPair(res)
+		if !isPair {
+			return nil, fmt.Errorf("page is not SxHTML, but %T/%v", res, res)
+		}
+		return page, nil
+	}
+	if check {
+		return checkLinks(st, render, assetsDir, source)
+	}
+
+	ex := sxsite.NewExporter(st, render).SetGenerator(sxhtml.NewGenerator().SetNewline())
+	if assetsDir != "" {
+		ex.SetAssets(os.DirFS(assetsDir))
+	}
+	report, err := ex.Export(outDir)
+	for _, fileName := range report.Written {
+		fmt.Println(fileName)
+	}
+	for _, nodeID := range report.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped node %q\n", nodeID)
+	}
+	return err
+}
+
+// checkLinks reports all broken links, found statically in the Sx source and
+// by rendering all pages.
+func checkLinks(st *site.Site, render sxsite.RenderFunc, assetsDir string, source []sx.Object) error {
+	lc := sxsite.NewLinkChecker(st).SetRender(render)
+	if assetsDir != "" {
+		lc.SetAssets(os.DirFS(assetsDir))
+	}
+	problems := lc.CheckSource(source...)
+	crawled, err := lc.Crawl()
+	problems = append(problems, crawled...)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if err == nil && len(problems) > 0 {
+		err = fmt.Errorf("%d broken links", len(problems))
+	}
+	return err
+}
+
+// readFile reads all Sx objects of the file.
+func readFile(fileName string) ([]sx.Object, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rd := sxreader.MakeReader(f)
+	var result []sx.Object
+	for {
+		obj, errRead := rd.Read()
+		if errors.Is(errRead, io.EOF) {
+			return result, nil
+		}
+		if errRead != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, errRead)
+		}
+		result = append(result, obj)
+	}
+}
@@ -0,0 +1,175 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/webs/site"
+)
+
+const testSite = `(site "Documentation"
+  (node "home" "Home" ""
+    (node "intro" "Introduction" "intro/")
+    (node "user" "User" "users/{id}/")
+    (node "imprint" "Imprint" "imprint/" :invisible)))`
+
+const testPages = `
+(defun page:home (node-id)
+  (list 'p (list 'a (list (cons 'href (url-for "intro"))) (site-node-title node-id))))
+(defun page:intro (node-id)
+  (list 'p (list 'a (list (cons 'href (make-url "style.css"))) (site-node-title node-id))))
+`
+
+func TestBuildSite(t *testing.T) {
+	t.Parallel()
+	spec, err := sxreader.MakeReader(strings.NewReader(testSite)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := buildSite(spec, "/docs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.Root().ID(); got != "home" {
+		t.Errorf("unexpected root %q", got)
+	}
+	if node := st.GetNode("intro"); node == nil || node.Title() != "Introduction" || !node.IsVisible() || node.Parent() != st.Root() {
+		t.Errorf("unexpected node %v", node)
+	}
+	if node := st.GetNode("imprint"); node == nil || node.IsVisible() {
+		t.Errorf("node imprint must be invisible: %v", node)
+	}
+	if ub := st.BuilderFor("user", "7"); ub == nil || ub.String() != "/docs/users/7/" {
+		t.Errorf("unexpected URL %v", ub)
+	}
+}
+
+func TestBuildSiteError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+	}{
+		{"no-site", `(node "home" "Home" "")`},
+		{"no-root", `(site "S")`},
+		{"two-roots", `(site "S" (node "a" "A" "") (node "b" "B" "b/"))`},
+		{"name-symbol", `(site S (node "home" "Home" ""))`},
+		{"short-node", `(site "S" (node "home" "Home"))`},
+		{"id-symbol", `(site "S" (node home "Home" ""))`},
+		{"child", `(site "S" (node "home" "Home" "" "intro"))`},
+		{"duplicate", `(site "S" (node "home" "Home" "" (node "home" "Home" "x/")))`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := sxreader.MakeReader(strings.NewReader(tc.src)).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = buildSite(spec, "/"); err == nil {
+				t.Error("error expected")
+			}
+		})
+	}
+}
+
+// writeTestFiles writes the site description, the pages, and the assets
+// into a new directory. It returns the site, read from its description.
+func writeTestFiles(t *testing.T, pages string) (st *site.Site, pageFile, assetsDir string) {
+	t.Helper()
+	dir := t.TempDir()
+	siteFile := filepath.Join(dir, "site.sxn")
+	pageFile = filepath.Join(dir, "pages.sx")
+	assetsDir = filepath.Join(dir, "static")
+	if err := os.Mkdir(assetsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		siteFile:                              testSite,
+		pageFile:                              pages,
+		filepath.Join(assetsDir, "style.css"): "p {}",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st, err := readSite(siteFile, "/docs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st, pageFile, assetsDir
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	st, pageFile, assetsDir := writeTestFiles(t, testPages)
+	outDir := t.TempDir()
+	for i := range 2 {
+		if err := run(st, outDir, assetsDir, false, []string{pageFile}); err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+	}
+	testcases := []struct {
+		fileName string
+		exp      string
+	}{
+		{"index.html", `<p><a href="intro/index.html">Home</a></p>`},
+		{"intro/index.html", `<p><a href="../style.css">Introduction</a></p>`},
+		{"style.css", "p {}"},
+	}
+	for _, tc := range testcases {
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(tc.fileName)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := string(data); got != tc.exp {
+			t.Errorf("%s: expected %q, but got %q", tc.fileName, tc.exp, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "imprint")); err == nil {
+		t.Error("node without a page must not be exported")
+	}
+
+	if err := run(st, t.TempDir(), assetsDir, true, []string{pageFile}); err != nil {
+		t.Errorf("check: %v", err)
+	}
+}
+
+func TestRunError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name  string
+		pages string
+		check bool
+	}{
+		{"syntax", `(defun page:home (node-id)`, false},
+		{"eval", `(undefined-function)`, false},
+		{"no-procedure", `(defvar page:home "home")`, false},
+		{"no-sxhtml", `(defun page:home (node-id) node-id)`, false},
+		{"broken-link", `(defun page:home (node-id) (list 'p (list 'a (list (cons 'href "/docs/missing/")) "x")))`, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			st, pageFile, assetsDir := writeTestFiles(t, tc.pages)
+			if err := run(st, t.TempDir(), assetsDir, tc.check, []string{pageFile}); err == nil {
+				t.Error("error expected")
+			}
+		})
+	}
+}
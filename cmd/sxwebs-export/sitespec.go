@@ -0,0 +1,76 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/webs/site"
+)
+
+// buildSite creates the site from its description
+// (site name (node id title pattern [:invisible] child...)).
+func buildSite(spec sx.Object, base string) (*site.Site, error) {
+	lst, isPair := sx.GetPair(spec)
+	if !isPair || lst == nil || !lst.Car().IsEqual(sx.MakeSymbol("site")) {
+		return nil, fmt.Errorf("not a site description: %v", spec)
+	}
+	args := lst.Tail()
+	if args == nil || args.Tail() == nil || args.Tail().Tail() != nil {
+		return nil, fmt.Errorf("site needs a name and a root node: %v", spec)
+	}
+	name, isString := sx.GetString(args.Car())
+	if !isString {
+		return nil, fmt.Errorf("site name is not a string, but %T/%v", args.Car(), args.Car())
+	}
+	root, err := buildNode(args.Tail().Car())
+	if err != nil {
+		return nil, err
+	}
+	return site.New(name.GetValue(), base, root)
+}
+
+func buildNode(spec sx.Object) (*site.Node, error) {
+	lst, isPair := sx.GetPair(spec)
+	if !isPair || lst == nil || !lst.Car().IsEqual(sx.MakeSymbol("node")) {
+		return nil, fmt.Errorf("not a node description: %v", spec)
+	}
+	var vals [3]string
+	elems := lst.Tail()
+	for i := range vals {
+		if elems == nil {
+			return nil, fmt.Errorf("node needs an id, a title, and a pattern: %v", spec)
+		}
+		s, isString := sx.GetString(elems.Car())
+		if !isString {
+			return nil, fmt.Errorf("node value %d is not a string, but %T/%v", i+1, elems.Car(), elems.Car())
+		}
+		vals[i] = s.GetValue()
+		elems = elems.Tail()
+	}
+	node := site.NewNode(vals[0], vals[1], vals[2])
+	for elem := range elems.Values() {
+		if elem.IsEqual(sx.MakeSymbol(":invisible")) {
+			node.SetInvisible()
+			continue
+		}
+		child, err := buildNode(elem)
+		if err != nil {
+			return nil, err
+		}
+		node.AddChild(child)
+	}
+	return node, nil
+}
@@ -61,11 +61,36 @@ var (
 // Generator is the object that allows to generate HTML.
 type Generator struct {
 	withNewline bool
+	xml         bool
+	hooks       map[string]ElementHook
 }
 
+// ElementHook returns additional content for an element, based on its
+// attributes. The content is placed before all other content of the element.
+type ElementHook func(attrs *sx.Pair) *sx.Pair
+
 // SetNewline will add new-line characters before certain tags.
 func (gen *Generator) SetNewline() *Generator { gen.withNewline = true; return gen }
 
+// SetXML will generate XML instead of HTML: elements without content are
+// closed with "/>", no element is treated as void or is ignored if empty,
+// and the document type emits an XML declaration.
+func (gen *Generator) SetXML() *Generator { gen.xml = true; return gen }
+
+// SetElementHook sets a hook for all elements with the given tag. A nil hook
+// removes the hook.
+func (gen *Generator) SetElementHook(tag string, hook ElementHook) *Generator {
+	if hook == nil {
+		delete(gen.hooks, tag)
+		return gen
+	}
+	if gen.hooks == nil {
+		gen.hooks = map[string]ElementHook{}
+	}
+	gen.hooks[tag] = hook
+	return gen
+}
+
 // NewGenerator creates a new generator.
 func NewGenerator() *Generator { return &Generator{} }
 
@@ -176,13 +201,17 @@ func (enc *myEncoder) printCommentObj(obj sx.Object) {
 
 func (enc *myEncoder) writeDoctype(elems *sx.Pair) {
 	// TODO: check for multiple doctypes, error on second
-	enc.pr.printString("<!DOCTYPE html>\n")
+	if enc.gen.xml {
+		enc.pr.printString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	} else {
+		enc.pr.printString("<!DOCTYPE html>\n")
+	}
 	enc.generateList(elems)
 }
 
 func (enc *myEncoder) writeTag(sym *sx.Symbol, elems *sx.Pair) {
 	tag := sym.GetValue()
-	if isIgnorableEmptyTag(tag) && ignoreEmptyStrings(elems) == nil {
+	if !enc.gen.xml && isIgnorableEmptyTag(tag) && ignoreEmptyStrings(elems) == nil {
 		return
 	}
 	withNewline := enc.gen.withNewline && isNewLineTag(tag)
@@ -192,16 +221,26 @@ func (enc *myEncoder) writeTag(sym *sx.Symbol, elems *sx.Pair) {
 	} else {
 		enc.pr.printStrings("<", tagName)
 	}
-	if attrs := getAttributes(elems); attrs != nil {
+	attrs := GetAttributes(elems)
+	if attrs != nil {
 		enc.writeAttributes(attrs)
 		elems = elems.Tail()
 	}
+	hook, hasHook := enc.gen.hooks[tag]
+	if enc.gen.xml && elems == nil && !hasHook {
+		enc.pr.printString("/>")
+		enc.lastWasTag = withNewline
+		return
+	}
 	enc.pr.printString(">")
-	if tags.IsVoid(tag) {
+	if !enc.gen.xml && tags.IsVoid(tag) {
 		enc.lastWasTag = withNewline
 		return
 	}
 
+	if hasHook {
+		enc.generateList(hook(attrs))
+	}
 	enc.generateList(elems)
 	if withNewline {
 		enc.pr.printStrings("</", tagName, ">\n")
@@ -251,7 +290,10 @@ func isAlwaysNewLineTag(tag string) bool {
 	return false
 }
 
-func getAttributes(lst *sx.Pair) *sx.Pair {
+// GetAttributes returns the attribute list of an element, given the list of
+// its attributes and content, i.e. the list after the tag. If the element
+// has no attributes, nil is returned.
+func GetAttributes(lst *sx.Pair) *sx.Pair {
 	if pair, isPair := sx.GetPair(lst.Car()); isPair && pair != nil {
 		if _, isAttr := sx.GetPair(pair.Car()); isAttr {
 			return pair
@@ -260,6 +302,49 @@ func getAttributes(lst *sx.Pair) *sx.Pair {
 	return nil
 }
 
+// GetAttribute returns the value of the attribute with the given key. As with
+// generated HTML, the first occurrence of a key is relevant, and an attribute
+// without a value is returned as an empty string. If the attribute was
+// deleted or is not found, false is returned.
+func GetAttribute(attrs *sx.Pair, key string) (string, bool) {
+	for val := range attrs.Values() {
+		pair, isPair := sx.GetPair(val)
+		if !isPair {
+			continue
+		}
+		sym, isSymbol := sx.GetSymbol(pair.Car())
+		if !isSymbol || sym.String() != key {
+			continue
+		}
+		if s, isValue := getAttributeValue(pair.Cdr()); isValue {
+			return s, true
+		}
+		return "", sx.IsNil(pair.Cdr())
+	}
+	return "", false
+}
+
+func getAttributeValue(cdr sx.Object) (string, bool) {
+	if sx.IsNil(cdr) {
+		return "", false
+	}
+	var obj sx.Object
+	if tail, isTail := sx.GetPair(cdr); isTail {
+		obj = tail.Car()
+	} else {
+		obj = cdr
+	}
+	switch o := obj.(type) {
+	case sx.String:
+		return strings.TrimSpace(o.GetValue()), true
+	case *sx.Symbol:
+		return strings.TrimSpace(o.GetValue()), true
+	case sx.Number:
+		return strings.TrimSpace(o.GoString()), true
+	}
+	return "", false
+}
+
 func (enc *myEncoder) writeAttributes(attrs *sx.Pair) {
 	length := attrs.Length()
 	found := make(map[string]struct{}, length)
@@ -280,24 +365,11 @@ func (enc *myEncoder) writeAttributes(attrs *sx.Pair) {
 		}
 		found[key] = struct{}{}
 		if cdr := pair.Cdr(); !sx.IsNil(cdr) {
-			var obj sx.Object
-			if tail, isTail := sx.GetPair(cdr); isTail {
-				obj = tail.Car()
-			} else {
-				obj = cdr
-			}
-			var s string
-			switch o := obj.(type) {
-			case sx.String:
-				s = o.GetValue()
-			case *sx.Symbol:
-				s = o.GetValue()
-			case sx.Number:
-				s = o.GoString()
-			default:
+			s, isValue := getAttributeValue(cdr)
+			if !isValue {
 				continue
 			}
-			a[key] = strings.TrimSpace(s)
+			a[key] = s
 		} else {
 			a[key] = ""
 			empty[key] = struct{}{}
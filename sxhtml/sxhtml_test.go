@@ -101,6 +101,100 @@ func TestWithNewline(t *testing.T) {
 	})
 }
 
+func TestXML(t *testing.T) {
+	testcases := []testcase{
+		{name: "Declaration", src: `(@@@@ (urlset ((xmlns . "ns")) (url (loc "a&b"))))`, exp: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"ns\"><url><loc>a&amp;b</loc></url></urlset>"},
+		{name: "EmptyElement", src: `(feed (link ((href . "x"))) (br))`, exp: `<feed><link href="x"/><br/></feed>`},
+		{name: "NoIgnoreEmpty", src: `(p "")`, exp: `<p></p>`},
+	}
+	checkTestcases(t, testcases, func() *sxhtml.Generator {
+		return sxhtml.NewGenerator().SetXML()
+	})
+}
+
+func TestElementHook(t *testing.T) {
+	testcases := []testcase{
+		{name: "Post", src: `(form ((method "post")) (p "A"))`, exp: `<form method="post"><input name="t" type="hidden"><p>A</p></form>`},
+		{name: "Get", src: `(form ((method "get")) (p "A"))`, exp: `<form method="get"><p>A</p></form>`},
+		{name: "NoAttr", src: `(form (p "A"))`, exp: `<form><p>A</p></form>`},
+		{name: "Nested", src: `(div (form ((method . POST))))`, exp: `<div><form method="POST"><input name="t" type="hidden"></form></div>`},
+	}
+	checkTestcases(t, testcases, func() *sxhtml.Generator {
+		return sxhtml.NewGenerator().SetElementHook("form", func(attrs *sx.Pair) *sx.Pair {
+			if method, found := sxhtml.GetAttribute(attrs, "method"); found && strings.EqualFold(method, "post") {
+				return sx.MakeList(sx.MakeList(
+					sxhtml.MakeSymbol("input"),
+					sx.MakeList(
+						sx.Cons(sxhtml.MakeSymbol("type"), sx.MakeString("hidden")),
+						sx.Cons(sxhtml.MakeSymbol("name"), sx.MakeString("t")),
+					),
+				))
+			}
+			return nil
+		})
+	})
+}
+
+func TestGetAttribute(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		src   string
+		key   string
+		exp   string
+		found bool
+	}{
+		{`()`, "a", "", false},
+		{`((a . "b"))`, "a", "b", true},
+		{`((a "b"))`, "a", "b", true},
+		{`((a . b))`, "a", "b", true},
+		{`((a . 7))`, "a", "7", true},
+		{`((a . " b "))`, "a", "b", true},
+		{`((a))`, "a", "", true},
+		{`((a ()) (a "b"))`, "a", "", false},
+		{`((a "1") (a "2"))`, "a", "1", true},
+		{`((b "1"))`, "a", "", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.src, func(t *testing.T) {
+			val, err := sxreader.MakeReader(strings.NewReader(tc.src)).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			attrs, _ := sx.GetPair(val)
+			got, found := sxhtml.GetAttribute(attrs, tc.key)
+			if got != tc.exp || found != tc.found {
+				t.Errorf("expected %q/%v, but got %q/%v", tc.exp, tc.found, got, found)
+			}
+		})
+	}
+}
+
+func TestGetAttributes(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		src string
+		exp string
+	}{
+		{`(br)`, "()"},
+		{`(p "a")`, "()"},
+		{`(p () "a")`, "()"},
+		{`(a ((href . "/")) "a")`, `((href . "/"))`},
+		{`(p (b "a"))`, "()"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.src, func(t *testing.T) {
+			val, err := sxreader.MakeReader(strings.NewReader(tc.src)).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			elem, _ := sx.GetPair(val)
+			if got := sxhtml.GetAttributes(elem.Tail()); got.String() != tc.exp {
+				t.Errorf("expected %s, but got %s", tc.exp, got)
+			}
+		})
+	}
+}
+
 func checkTestcases(t *testing.T, testcases []testcase, newGen func() *sxhtml.Generator) {
 	for _, tc := range testcases {
 		name := tc.name
@@ -0,0 +1,93 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBudgetSteps(t *testing.T) {
+	t.Parallel()
+	obs := budgetObserver{ctx: context.Background(), maxSteps: 3}
+	for i := range 3 {
+		if _, err := obs.BeforeExecution(nil, nil); err != nil {
+			t.Fatalf("step %d: unexpected error %v", i+1, err)
+		}
+	}
+	if _, err := obs.BeforeExecution(nil, nil); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected budget error, but got %v", err)
+	}
+	if len(obs.trace) != 4 {
+		t.Errorf("expected a trace of the four started expressions, but got %q", obs.trace)
+	}
+	for range 4 {
+		obs.AfterExecution(nil, nil, nil, ErrBudgetExceeded)
+	}
+	if len(obs.stack) != 0 {
+		t.Errorf("every started expression must be popped, but got %d", len(obs.stack))
+	}
+}
+
+func TestBudgetContext(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	obs := budgetObserver{ctx: ctx}
+	var err error
+	for range checkInterval {
+		if _, err = obs.BeforeExecution(nil, nil); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation after %d steps, but got %v", checkInterval, err)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	t.Parallel()
+	errEval := errors.New("eval")
+	if err := contextError(context.Background(), errEval); err != errEval {
+		t.Errorf("active context must not change the error, but got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := contextError(ctx, errEval); !errors.Is(err, errEval) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected both errors, but got %v", err)
+	}
+}
+
+func TestHandleErrorStarted(t *testing.T) {
+	t.Parallel()
+	ev := evaluator{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	tw := &trackingWriter{ResponseWriter: httptest.NewRecorder()}
+	tw.WriteHeader(http.StatusEarlyHints)
+	if responseStarted(tw) {
+		t.Error("informational status must not start the response")
+	}
+
+	rec := httptest.NewRecorder()
+	tw = &trackingWriter{ResponseWriter: rec}
+	tw.Write([]byte("partial"))
+	ev.handleError(tw, httptest.NewRequest("GET", "/", nil), errors.New("late"))
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("started response must not be changed, but got %d %q", rec.Code, rec.Body.String())
+	}
+}
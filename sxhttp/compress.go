@@ -0,0 +1,246 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Supported content encodings.
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// DefaultMinCompressSize is the minimum size of a response body, so that it
+// will be compressed.
+const DefaultMinCompressSize = 1024
+
+// Compress returns a handler that compresses responses with gzip or deflate,
+// as negotiated by the Accept-Encoding header of the request.
+//
+// Responses smaller than minSize bytes are sent uncompressed. If minSize is
+// not positive, DefaultMinCompressSize is used. Only textual content types are
+// compressed. If the next handler flushes the response, the compressor is
+// flushed too, so that streamed content reaches the client.
+//
+// A strong ETag of a compressed response gets a suffix with the encoding.
+// The suffix is removed from If-None-Match before the next handler is
+// called, so that Conditional can be used within the next handler.
+func Compress(next http.Handler, minSize int) http.Handler {
+	if minSize <= 0 {
+		minSize = DefaultMinCompressSize
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := compressWriter{w: w, encoding: encoding, minSize: minSize}
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			if stripped := strings.ReplaceAll(inm, etagSuffix(encoding)+`"`, `"`); stripped != inm {
+				r = r.Clone(r.Context())
+				r.Header.Set("If-None-Match", stripped)
+				cw.suffixed = true
+			}
+		}
+		defer cw.close()
+		next.ServeHTTP(&cw, r)
+	})
+}
+
+// negotiateEncoding returns the preferred supported encoding, or the empty
+// string if the identity encoding should be used.
+func negotiateEncoding(accept string) string {
+	var qGzip, qDeflate, qAny float64 = -1, -1, -1
+	for elem := range strings.SplitSeq(accept, ",") {
+		coding, params, _ := strings.Cut(elem, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if key, val, found := strings.Cut(strings.TrimSpace(params), "="); found && strings.TrimSpace(key) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				q = v
+			}
+		}
+		switch coding {
+		case EncodingGzip:
+			qGzip = max(qGzip, q)
+		case EncodingDeflate:
+			qDeflate = max(qDeflate, q)
+		case "*":
+			qAny = max(qAny, q)
+		}
+	}
+	// An explicitly listed coding overrides "*".
+	if qGzip < 0 {
+		qGzip = qAny
+	}
+	if qDeflate < 0 {
+		qDeflate = qAny
+	}
+	switch {
+	case qGzip > 0 && qGzip >= qDeflate:
+		return EncodingGzip
+	case qDeflate > 0:
+		return EncodingDeflate
+	}
+	return ""
+}
+
+func etagSuffix(encoding string) string { return "-" + encoding }
+
+type compressWriter struct {
+	w        http.ResponseWriter
+	encoding string
+	minSize  int
+	status   int
+	buf      []byte
+	decided  bool
+	suffixed bool           // If-None-Match contained an ETag with suffix
+	comp     io.WriteCloser // nil, if response is not compressed
+}
+
+func (cw *compressWriter) Header() http.Header { return cw.w.Header() }
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 || cw.decided {
+		return
+	}
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		cw.w.WriteHeader(status) // Informational, the final status follows
+		return
+	}
+	cw.status = status
+	if !bodyAllowed(status) {
+		cw.decide(false)
+	}
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) >= cw.minSize {
+			if err := cw.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if cw.comp != nil {
+		return cw.comp.Write(p)
+	}
+	return cw.w.Write(p)
+}
+
+// decide whether to compress the response, and send the buffered data.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	h := cw.w.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if compress && bodyAllowed(cw.status) && h.Get("Content-Encoding") == "" && isCompressible(h.Get("Content-Type")) {
+		switch cw.encoding {
+		case EncodingGzip:
+			cw.comp = gzip.NewWriter(cw.w)
+		case EncodingDeflate:
+			cw.comp = zlib.NewWriter(cw.w) // HTTP "deflate" is the zlib format
+		}
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		cw.addETagSuffix()
+	} else if cw.status == http.StatusNotModified && cw.suffixed {
+		// The client has cached the compressed response
+		cw.addETagSuffix()
+	}
+	cw.w.WriteHeader(cw.status)
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.comp != nil {
+		_, err = cw.comp.Write(cw.buf)
+	} else {
+		_, err = cw.w.Write(cw.buf)
+	}
+	cw.buf = nil
+	return err
+}
+
+func (cw *compressWriter) addETagSuffix() {
+	h := cw.w.Header()
+	if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`) {
+		h.Set("ETag", etag[:len(etag)-1]+etagSuffix(cw.encoding)+`"`)
+	}
+}
+
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case MediaJSON, MediaSexpr, "application/javascript", "application/xml", "image/svg+xml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+json")
+}
+
+// Flush sends all buffered data to the client. If the response was not
+// already sent uncompressed, it will be compressed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(true)
+	}
+	if f, isFlusher := cw.comp.(interface{ Flush() error }); isFlusher {
+		f.Flush()
+	}
+	http.NewResponseController(cw.w).Flush()
+}
+
+// Unwrap returns the original response writer, for http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.w }
+
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			return // Nothing was written, let net/http handle the response
+		}
+		cw.decide(len(cw.buf) >= cw.minSize)
+	}
+	if cw.comp != nil {
+		cw.comp.Close()
+	}
+}
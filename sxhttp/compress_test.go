@@ -0,0 +1,196 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("<p>Hello, World!</p>", 100)
+	testcases := []struct {
+		name     string
+		accept   string
+		body     string
+		ctype    string
+		encoding string
+	}{
+		{"no-accept", "", long, "text/html", ""},
+		{"gzip", "gzip", long, "text/html", sxhttp.EncodingGzip},
+		{"deflate", "deflate", long, "text/html", sxhttp.EncodingDeflate},
+		{"prefer-gzip", "deflate, gzip", long, "text/html", sxhttp.EncodingGzip},
+		{"prefer-q", "deflate;q=1, gzip;q=0.5", long, "text/html", sxhttp.EncodingDeflate},
+		{"gzip-disabled", "gzip;q=0", long, "text/html", ""},
+		{"any", "*", long, "text/html", sxhttp.EncodingGzip},
+		{"any-but-gzip", "*;q=1, gzip;q=0", long, "text/html", sxhttp.EncodingDeflate},
+		{"explicit-over-any", "*;q=0.5, deflate", long, "text/html", sxhttp.EncodingDeflate},
+		{"any-disabled", "*;q=0", long, "text/html", ""},
+		{"small", "gzip", "<p>Hello</p>", "text/html", ""},
+		{"image", "gzip", long, "image/png", ""},
+		{"sniffed", "gzip", long, "", sxhttp.EncodingGzip},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := sxhttp.Compress(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tc.ctype != "" {
+					w.Header().Set("Content-Type", tc.ctype)
+				}
+				for i := 0; i < len(tc.body); i += 100 {
+					io.WriteString(w, tc.body[i:min(i+100, len(tc.body))])
+				}
+			}), 0)
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept-Encoding", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			resp := w.Result()
+			if got := resp.Header.Get("Content-Encoding"); got != tc.encoding {
+				t.Errorf("expected encoding %q, but got %q", tc.encoding, got)
+			}
+			if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("expected Vary header, but got %q", got)
+			}
+			var rd io.Reader = resp.Body
+			var err error
+			switch tc.encoding {
+			case sxhttp.EncodingGzip:
+				rd, err = gzip.NewReader(rd)
+			case sxhttp.EncodingDeflate:
+				rd, err = zlib.NewReader(rd)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(rd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.body {
+				t.Errorf("body differs: %q", got)
+			}
+		})
+	}
+}
+
+func TestCompressConditional(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("<p>Hello, World!</p>", 100)
+	h := sxhttp.Compress(sxhttp.Conditional(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, long)
+	})), 0)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	etag := w.Header().Get("ETag")
+	if !strings.HasSuffix(etag, `-gzip"`) {
+		t.Fatalf("expected ETag with encoding suffix, but got %q", etag)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, but got %d", w.Code)
+	}
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("expected ETag %q, but got %q", etag, got)
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	t.Parallel()
+	h := sxhttp.Compress(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: 1\n\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Error(err)
+		}
+		io.WriteString(w, "data: 2\n\n")
+	}), 0)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !w.Flushed {
+		t.Error("response was not flushed")
+	}
+	rd, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "data: 1\n\ndata: 2\n\n"; string(got) != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+}
+
+func TestCompressInformational(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("<p>Hello, World!</p>", 100)
+	srv := httptest.NewServer(sxhttp.Compress(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, long)
+	}), 0))
+	defer srv.Close()
+
+	var informational []int
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			informational = append(informational, code)
+			return nil
+		},
+	}
+	r, err := http.NewRequestWithContext(httptrace.WithClientTrace(t.Context(), trace), "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Accept-Encoding", "gzip")
+	resp, err := srv.Client().Transport.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if len(informational) != 1 || informational[0] != http.StatusEarlyHints {
+		t.Errorf("expected status 103 before the response, but got %v", informational)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, but got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != sxhttp.EncodingGzip {
+		t.Errorf("expected gzip encoding, but got %q", got)
+	}
+}
@@ -0,0 +1,252 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+)
+
+// Conditional returns a handler that supports conditional GET requests.
+//
+// The response of GET and HEAD requests is buffered. If the next handler did
+// not set an ETag, a strong ETag is computed from the response body. If the
+// request contains a matching If-None-Match header, or an If-Modified-Since
+// header that is not older than the Last-Modified header of the response,
+// status 304 is sent without a body.
+//
+// If the next handler sets an ETag before writing the body, e.g. by using
+// "response-etag", the body is discarded as soon as a match is detected.
+// If the next handler flushes the response, buffering stops and no ETag is
+// computed.
+func Conditional(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := conditionalWriter{w: w, r: r}
+		next.ServeHTTP(&cw, r)
+		cw.finish()
+	})
+}
+
+type conditionalWriter struct {
+	w           http.ResponseWriter
+	r           *http.Request
+	buf         bytes.Buffer
+	status      int
+	notModified bool // a 304 will be / was sent
+	passthrough bool // buffering was stopped, all data goes to w
+}
+
+func (cw *conditionalWriter) Header() http.Header { return cw.w.Header() }
+
+func (cw *conditionalWriter) WriteHeader(status int) {
+	if cw.status != 0 || cw.passthrough {
+		return
+	}
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		cw.w.WriteHeader(status) // Informational, the final status follows
+		return
+	}
+	cw.status = status
+	if status == http.StatusOK && cw.w.Header().Get("ETag") != "" && isNotModified(cw.r, cw.w.Header()) {
+		cw.notModified = true
+	}
+}
+
+func (cw *conditionalWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case cw.passthrough:
+		return cw.w.Write(p)
+	case cw.notModified:
+		return len(p), nil
+	}
+	return cw.buf.Write(p)
+}
+
+// Flush stops buffering and sends everything to the client.
+func (cw *conditionalWriter) Flush() {
+	if !cw.passthrough && !cw.notModified {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		cw.w.WriteHeader(cw.status)
+		cw.buf.WriteTo(cw.w)
+		cw.passthrough = true
+	}
+	http.NewResponseController(cw.w).Flush()
+}
+
+// Hijack takes over the connection, e.g. for a WebSocket. Everything that was
+// buffered is discarded, and no response is sent afterwards.
+func (cw *conditionalWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(cw.w).Hijack()
+	if err == nil {
+		cw.buf.Reset()
+		cw.passthrough = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap returns the original response writer, for http.ResponseController.
+func (cw *conditionalWriter) Unwrap() http.ResponseWriter { return cw.w }
+
+func (cw *conditionalWriter) finish() {
+	if cw.passthrough {
+		return
+	}
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	h := cw.w.Header()
+	if cw.status == http.StatusOK && !cw.notModified {
+		if h.Get("ETag") == "" {
+			h.Set("ETag", ComputeETag(cw.buf.Bytes()))
+		}
+		cw.notModified = isNotModified(cw.r, h)
+	}
+	if cw.notModified {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		cw.w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	cw.w.WriteHeader(cw.status)
+	cw.buf.WriteTo(cw.w)
+}
+
+// ComputeETag returns a strong ETag for the given content.
+func ComputeETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+}
+
+// isNotModified checks the request headers against the response headers.
+func isNotModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		return etag != "" && matchETag(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		modified, err := http.ParseTime(h.Get("Last-Modified"))
+		return err == nil && !modified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// matchETag performs a weak comparison of an ETag against the value of an
+// If-None-Match header.
+func matchETag(inm, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(inm, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ----- Builtins ------------------------------------------------------------
+
+// CacheControl is a builtin that sets the Cache-Control header of the
+// response writer. All directives are strings or symbols:
+// (response-cache-control w 'public "max-age=3600").
+var CacheControl = sxeval.Builtin{
+	Name:     "response-cache-control",
+	MinArity: 1,
+	MaxArity: -1,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		directives := make([]string, 0, len(args)-1)
+		for i := 1; i < len(args); i++ {
+			directive, errKey := getBuiltinKey(args[i], i)
+			if errKey != nil {
+				return sx.Nil(), errKey
+			}
+			directives = append(directives, directive)
+		}
+		if len(directives) == 0 {
+			w.GetValue().Header().Del("Cache-Control")
+		} else {
+			w.GetValue().Header().Set("Cache-Control", strings.Join(directives, ", "))
+		}
+		return sx.Nil(), nil
+	},
+}
+
+// ETag is a builtin that sets a strong ETag for the response, based on the
+// given version string. It must be called before the body is written.
+var ETag = sxeval.Builtin{
+	Name:     "response-etag",
+	MinArity: 2,
+	MaxArity: 2,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		version, err := getBuiltinKey(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		if strings.ContainsAny(version, "\"\\") {
+			return sx.Nil(), fmt.Errorf("invalid ETag version: %q", version)
+		}
+		w.GetValue().Header().Set("ETag", `"`+version+`"`)
+		return sx.Nil(), nil
+	},
+}
+
+// LastModified is a builtin that sets the Last-Modified header of the
+// response. The time is given in milliseconds since the Unix epoch.
+var LastModified = sxeval.Builtin{
+	Name:     "response-last-modified",
+	MinArity: 2,
+	MaxArity: 2,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		ms, err := getBuiltinInt(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		w.GetValue().Header().Set("Last-Modified", time.UnixMilli(ms).UTC().Format(http.TimeFormat))
+		return sx.Nil(), nil
+	},
+}
@@ -0,0 +1,154 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestConditional(t *testing.T) {
+	t.Parallel()
+	const body = "<p>Hello</p>"
+	const lastModified = "Mon, 05 Oct 2026 10:00:00 GMT"
+	h := sxhttp.Conditional(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versioned" {
+			w.Header().Set("ETag", `"v1"`)
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		io.WriteString(w, body)
+	}))
+	etag := sxhttp.ComputeETag([]byte(body))
+
+	testcases := []struct {
+		name   string
+		path   string
+		header string
+		value  string
+		status int
+		body   string
+	}{
+		{"plain", "/", "", "", http.StatusOK, body},
+		{"match", "/", "If-None-Match", etag, http.StatusNotModified, ""},
+		{"match-list", "/", "If-None-Match", `"x", ` + etag, http.StatusNotModified, ""},
+		{"match-weak", "/", "If-None-Match", "W/" + etag, http.StatusNotModified, ""},
+		{"match-any", "/", "If-None-Match", "*", http.StatusNotModified, ""},
+		{"no-match", "/", "If-None-Match", `"x"`, http.StatusOK, body},
+		{"versioned", "/versioned", "If-None-Match", `"v1"`, http.StatusNotModified, ""},
+		{"versioned-no-match", "/versioned", "If-None-Match", etag, http.StatusOK, body},
+		{"modified-since", "/", "If-Modified-Since", lastModified, http.StatusNotModified, ""},
+		{"modified-since-older", "/", "If-Modified-Since", "Mon, 05 Oct 2026 09:00:00 GMT", http.StatusOK, body},
+		{"modified-since-invalid", "/", "If-Modified-Since", "yesterday", http.StatusOK, body},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tc.status {
+				t.Errorf("expected status %d, but got %d", tc.status, w.Code)
+			}
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("expected body %q, but got %q", tc.body, got)
+			}
+			if w.Header().Get("ETag") == "" {
+				t.Error("missing ETag")
+			}
+		})
+	}
+}
+
+func TestConditionalPost(t *testing.T) {
+	t.Parallel()
+	h := sxhttp.Conditional(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "posted")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+	if etag := w.Header().Get("ETag"); etag != "" {
+		t.Errorf("POST must not have an ETag, but got %q", etag)
+	}
+}
+
+func TestConditionalInformational(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(sxhttp.Conditional(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		io.WriteString(w, "<p>Hello</p>")
+	})))
+	defer srv.Close()
+
+	var informational []int
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			informational = append(informational, code)
+			return nil
+		},
+	}
+	r, err := http.NewRequestWithContext(httptrace.WithClientTrace(t.Context(), trace), "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Transport.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if len(informational) != 1 || informational[0] != http.StatusEarlyHints {
+		t.Errorf("expected status 103 before the response, but got %v", informational)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+		t.Errorf("expected status 200 with an ETag, but got %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestConditionalHijack(t *testing.T) {
+	t.Parallel()
+	const raw = "HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked"
+	srv := httptest.NewServer(sxhttp.Conditional(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "buffered")
+		conn, brw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		brw.WriteString(raw)
+		brw.Flush()
+	})))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(body); got != "hijacked" {
+		t.Errorf("expected the hijacked response, but got %q", got)
+	}
+}
@@ -0,0 +1,256 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+)
+
+// Symbols that describe the state of a context.
+var (
+	SymCanceled         = sx.MakeSymbol("canceled")
+	SymDeadlineExceeded = sx.MakeSymbol("deadline-exceeded")
+)
+
+// ContextErr is a builtin that returns nil, if the context is still active.
+// Otherwise it returns the symbol "canceled" or "deadline-exceeded".
+var ContextErr = sxeval.Builtin{
+	Name:     "context-err",
+	MinArity: 1,
+	MaxArity: 1,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		ctx, err := GetBuiltinContext(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		return contextErrToSx(ctx.val.Err()), nil
+	},
+}
+
+func contextErrToSx(err error) sx.Object {
+	switch {
+	case err == nil:
+		return sx.Nil()
+	case errors.Is(err, context.Canceled):
+		return SymCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return SymDeadlineExceeded
+	}
+	return sx.MakeString(err.Error())
+}
+
+// ContextDeadline is a builtin that returns the deadline of a context as the
+// number of milliseconds since the Unix epoch, or nil if there is no deadline.
+var ContextDeadline = sxeval.Builtin{
+	Name:     "context-deadline",
+	MinArity: 1,
+	MaxArity: 1,
+	TestPure: sxeval.AssertPure,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		ctx, err := GetBuiltinContext(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		if deadline, hasDeadline := ctx.val.Deadline(); hasDeadline {
+			return sx.Int64(deadline.UnixMilli()), nil
+		}
+		return sx.Nil(), nil
+	},
+}
+
+// ContextWithCancel is a builtin that derives a new context from the given
+// one. It can be cancelled by the builtin "context-cancel".
+var ContextWithCancel = sxeval.Builtin{
+	Name:     "context-with-cancel",
+	MinArity: 1,
+	MaxArity: 1,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		ctx, err := GetBuiltinContext(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		newCtx, cancel := context.WithCancel(ctx.val)
+		return SxContext{val: newCtx, cancel: &cancel}, nil
+	},
+}
+
+// ContextWithTimeout is a builtin that derives a new context from the given
+// one, which will be cancelled after the given number of milliseconds. It can
+// be cancelled earlier by the builtin "context-cancel".
+var ContextWithTimeout = sxeval.Builtin{
+	Name:     "context-with-timeout",
+	MinArity: 2,
+	MaxArity: 2,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		ctx, err := GetBuiltinContext(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		ms, err := getBuiltinInt(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		newCtx, cancel := context.WithTimeout(ctx.val, time.Duration(ms)*time.Millisecond)
+		return SxContext{val: newCtx, cancel: &cancel}, nil
+	},
+}
+
+// ContextCancel is a builtin that cancels a context, which was created by
+// "context-with-cancel" or "context-with-timeout". Other contexts are left
+// unchanged.
+var ContextCancel = sxeval.Builtin{
+	Name:     "context-cancel",
+	MinArity: 1,
+	MaxArity: 1,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		ctx, err := GetBuiltinContext(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		if ctx.cancel != nil {
+			(*ctx.cancel)()
+		}
+		return sx.Nil(), nil
+	},
+}
+
+func getBuiltinInt(arg sx.Object, pos int) (int64, error) {
+	num, err := sxbuiltins.GetNumber(arg, pos)
+	if err != nil {
+		return 0, err
+	}
+	if i, isInt := num.(sx.Int64); isInt {
+		return int64(i), nil
+	}
+	return 0, fmt.Errorf("argument %d is not an integer, but %T/%v", pos+1, arg, arg)
+}
+
+// getBuiltinKey returns a string or a symbol as a string.
+func getBuiltinKey(arg sx.Object, pos int) (string, error) {
+	if sym, isSymbol := sx.GetSymbol(arg); isSymbol {
+		return sym.GetValue(), nil
+	}
+	s, err := sxbuiltins.GetString(arg, pos)
+	if err != nil {
+		return "", err
+	}
+	return s.GetValue(), nil
+}
+
+// ----- Context values ------------------------------------------------------
+
+// ContextValueConverter converts a value stored in a context.Context into a
+// Sx object. If conversion is not possible, false must be returned.
+type ContextValueConverter func(any) (sx.Object, bool)
+
+// ContextKeys is a registry of context keys, that are exported to Sx code.
+//
+// Go middleware typically stores values in a request context under an
+// unexported key. To make them accessible, the key is registered under a
+// name, together with a function to convert the value.
+type ContextKeys struct {
+	mx   sync.RWMutex
+	keys map[string]contextKey
+}
+
+type contextKey struct {
+	key  any
+	conv ContextValueConverter
+}
+
+// NewContextKeys creates a new, empty registry of context keys.
+func NewContextKeys() *ContextKeys {
+	return &ContextKeys{keys: map[string]contextKey{}}
+}
+
+// Register the given key under the given name. If conv is nil, the function
+// ContextValueToSx is used to convert a value.
+func (ck *ContextKeys) Register(name string, key any, conv ContextValueConverter) *ContextKeys {
+	if conv == nil {
+		conv = ContextValueToSx
+	}
+	ck.mx.Lock()
+	ck.keys[name] = contextKey{key: key, conv: conv}
+	ck.mx.Unlock()
+	return ck
+}
+
+// Lookup returns the value of the context that is stored under the key
+// registered with the given name.
+func (ck *ContextKeys) Lookup(ctx context.Context, name string) (sx.Object, bool) {
+	ck.mx.RLock()
+	ckey, found := ck.keys[name]
+	ck.mx.RUnlock()
+	if !found {
+		return nil, false
+	}
+	val := ctx.Value(ckey.key)
+	if val == nil {
+		return nil, false
+	}
+	return ckey.conv(val)
+}
+
+// ContextValueToSx converts basic Go values into Sx objects.
+func ContextValueToSx(val any) (sx.Object, bool) {
+	switch v := val.(type) {
+	case sx.Object:
+		return v, true
+	case string:
+		return sx.MakeString(v), true
+	case fmt.Stringer:
+		return sx.MakeString(v.String()), true
+	case int:
+		return sx.Int64(v), true
+	case int64:
+		return sx.Int64(v), true
+	case bool:
+		return sx.MakeBoolean(v), true
+	}
+	return nil, false
+}
+
+// MakeContextValueBuiltin returns a builtin that provides the
+// (context-value ctx name) function. Name is a string or a symbol that was
+// registered at the given registry. If there is no value, nil is returned.
+func MakeContextValueBuiltin(ck *ContextKeys) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "context-value",
+		MinArity: 2,
+		MaxArity: 2,
+		TestPure: sxeval.AssertPure,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			ctx, err := GetBuiltinContext(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			name, err := getBuiltinKey(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			if obj, found := ck.Lookup(ctx.val, name); found {
+				return obj, nil
+			}
+			return sx.Nil(), nil
+		},
+	}
+}
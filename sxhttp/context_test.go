@@ -0,0 +1,86 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"context"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestContextCancel(t *testing.T) {
+	t.Parallel()
+	ctx := sxhttp.MakeContext(context.Background())
+	derived, err := sxhttp.ContextWithCancel.Fn1(nil, ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := sxhttp.ContextErr.Fn1(nil, derived, nil); !sx.IsNil(got) {
+		t.Errorf("active context must have no error, but got %v", got)
+	}
+	if _, err = sxhttp.ContextCancel.Fn1(nil, derived, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := sxhttp.ContextErr.Fn1(nil, derived, nil); !sxhttp.SymCanceled.IsEqual(got) {
+		t.Errorf("cancelled context must return %v, but got %v", sxhttp.SymCanceled, got)
+	}
+	if got, _ := sxhttp.ContextErr.Fn1(nil, ctx, nil); !sx.IsNil(got) {
+		t.Errorf("parent context must not be cancelled, but got %v", got)
+	}
+}
+
+func TestContextComparable(t *testing.T) {
+	t.Parallel()
+	ctx := sxhttp.MakeContext(context.Background())
+	derived, err := sxhttp.ContextWithTimeout.Fn(nil, sx.Vector{ctx, sx.Int64(1000)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sxhttp.ContextCancel.Fn1(nil, derived, nil)
+	if derived == sx.Object(ctx) {
+		t.Error("derived context must differ from its parent")
+	}
+	seen := map[sx.Object]bool{ctx: true, derived: true}
+	if !seen[derived] || len(seen) != 2 {
+		t.Errorf("contexts must be usable as map keys, but got %v", seen)
+	}
+}
+
+type userKey struct{}
+
+func TestContextValue(t *testing.T) {
+	t.Parallel()
+	ck := sxhttp.NewContextKeys().Register("user", userKey{}, nil)
+	ctx := context.WithValue(context.Background(), userKey{}, "detlef")
+	if got, found := ck.Lookup(ctx, "user"); !found || !got.IsEqual(sx.MakeString("detlef")) {
+		t.Errorf("expected %q, but got %v/%v", "detlef", got, found)
+	}
+	if got, found := ck.Lookup(ctx, "unknown"); found {
+		t.Errorf("unregistered name must not be found, but got %v", got)
+	}
+	if got, found := ck.Lookup(context.Background(), "user"); found {
+		t.Errorf("missing value must not be found, but got %v", got)
+	}
+
+	bi := sxhttp.MakeContextValueBuiltin(ck)
+	got, err := bi.Fn(nil, sx.Vector{sxhttp.MakeContext(ctx), sx.MakeSymbol("user")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsEqual(sx.MakeString("detlef")) {
+		t.Errorf("expected %q, but got %v", "detlef", got)
+	}
+}
@@ -0,0 +1,208 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+)
+
+// CSRF protects against cross-site request forgery.
+//
+// All requests with an unsafe method, e.g. POST, are checked. First, the
+// headers Sec-Fetch-Site and Origin must not denote a cross-origin request.
+// Second, the request must contain a valid token, either as a form field or
+// as a header. The token is derived from a random value that is stored in a
+// cookie, signed with a secret key (signed double-submit cookie).
+type CSRF struct {
+	key        []byte
+	cop        *http.CrossOriginProtection
+	cookieName string
+	fieldName  string
+	headerName string
+}
+
+// ErrCSRFToken signals a missing or invalid CSRF token.
+var ErrCSRFToken = errors.New("missing or invalid CSRF token")
+
+// Default names used for CSRF protection.
+const (
+	DefaultCSRFCookie = "csrf"
+	DefaultCSRFField  = "csrf-token"
+	DefaultCSRFHeader = "X-CSRF-Token"
+)
+
+// NewCSRF creates a new CSRF protection, using the given secret key to sign
+// tokens.
+func NewCSRF(key []byte) *CSRF {
+	return &CSRF{
+		key:        key,
+		cop:        http.NewCrossOriginProtection(),
+		cookieName: DefaultCSRFCookie,
+		fieldName:  DefaultCSRFField,
+		headerName: DefaultCSRFHeader,
+	}
+}
+
+// SetNames changes the names of the cookie, the form field, and the header.
+// Empty strings leave the corresponding name unchanged.
+func (c *CSRF) SetNames(cookie, field, header string) *CSRF {
+	if cookie != "" {
+		c.cookieName = cookie
+	}
+	if field != "" {
+		c.fieldName = field
+	}
+	if header != "" {
+		c.headerName = header
+	}
+	return c
+}
+
+// AddTrustedOrigin allows cross-origin requests from the given origin, e.g.
+// "https://example.com".
+func (c *CSRF) AddTrustedOrigin(origin string) error { return c.cop.AddTrustedOrigin(origin) }
+
+// FieldName returns the name of the form field that contains the token.
+func (c *CSRF) FieldName() string { return c.fieldName }
+
+type csrfCtxKey struct{}
+
+// Wrap returns a handler that checks all unsafe requests, before they are
+// passed to the next handler. It also makes sure that the client receives a
+// CSRF cookie.
+func (c *CSRF) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce, valid := c.cookieNonce(r)
+		if !isSafeMethod(r.Method) {
+			if err := c.cop.Check(r); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if !valid || !c.checkToken(r, nonce) {
+				http.Error(w, ErrCSRFToken.Error(), http.StatusForbidden)
+				return
+			}
+		}
+		if !valid {
+			nonce = rand.Text()
+			http.SetCookie(w, &http.Cookie{
+				Name:     c.cookieName,
+				Value:    nonce,
+				Path:     "/",
+				Secure:   r.TLS != nil,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		ctx := context.WithValue(r.Context(), csrfCtxKey{}, nonce)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func (c *CSRF) cookieNonce(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(c.cookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+func (c *CSRF) checkToken(r *http.Request, nonce string) bool {
+	token := r.Header.Get(c.headerName)
+	if token == "" {
+		token = r.PostFormValue(c.fieldName)
+	}
+	got, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && hmac.Equal(got, c.sign(nonce))
+}
+
+func (c *CSRF) sign(nonce string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	io.WriteString(mac, nonce)
+	return mac.Sum(nil)
+}
+
+// Token returns the CSRF token for the given request. The request must have
+// been passed through the handler returned by Wrap. Otherwise, an empty string
+// is returned.
+func (c *CSRF) Token(r *http.Request) string {
+	nonce, hasNonce := r.Context().Value(csrfCtxKey{}).(string)
+	if !hasNonce {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(c.sign(nonce))
+}
+
+// FormHook returns a hook for a sxhtml.Generator, that adds a hidden input
+// field with the CSRF token to every form with method "post".
+//
+//	gen := sxhtml.NewGenerator().SetElementHook("form", csrf.FormHook(r))
+func (c *CSRF) FormHook(r *http.Request) sxhtml.ElementHook {
+	token := c.Token(r)
+	return func(attrs *sx.Pair) *sx.Pair {
+		if token == "" {
+			return nil
+		}
+		if method, found := sxhtml.GetAttribute(attrs, "method"); !found || !strings.EqualFold(method, http.MethodPost) {
+			return nil
+		}
+		return sx.MakeList(sx.MakeList(
+			sxhtml.MakeSymbol("input"),
+			sx.MakeList(
+				sx.Cons(sxhtml.MakeSymbol("type"), sx.MakeString("hidden")),
+				sx.Cons(sxhtml.MakeSymbol("name"), sx.MakeString(c.fieldName)),
+				sx.Cons(sxhtml.MakeSymbol("value"), sx.MakeString(token)),
+			),
+		))
+	}
+}
+
+// MakeCSRFTokenBuiltin returns a builtin that provides the (csrf-token r)
+// function. It returns the CSRF token, e.g. to be sent as a header by
+// JavaScript code.
+func MakeCSRFTokenBuiltin(c *CSRF) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "csrf-token",
+		MinArity: 1,
+		MaxArity: 1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			r, err := GetBuiltinRequest(arg, 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			return sx.MakeString(c.Token(r.GetValue())), nil
+		},
+	}
+}
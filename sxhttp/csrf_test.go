@@ -0,0 +1,84 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestCSRF(t *testing.T) {
+	t.Parallel()
+	csrf := sxhttp.NewCSRF([]byte("a very secret key"))
+	var form string
+	h := csrf.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sb strings.Builder
+		gen := sxhtml.NewGenerator().SetElementHook("form", csrf.FormHook(r))
+		formObj := sx.MakeList(
+			sxhtml.MakeSymbol("form"),
+			sx.MakeList(sx.Cons(sxhtml.MakeSymbol("method"), sx.MakeString("post"))),
+		)
+		if err := gen.WriteHTML(&sb, formObj); err != nil {
+			t.Error(err)
+		}
+		form = sb.String()
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected one cookie, but got %v", cookies)
+	}
+	_, after, found := strings.Cut(form, `value="`)
+	if !found {
+		t.Fatalf("no token in form: %q", form)
+	}
+	token, _, _ := strings.Cut(after, `"`)
+
+	post := func(token string, header http.Header) int {
+		body := url.Values{csrf.FieldName(): {token}}.Encode()
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for key, vals := range header {
+			r.Header[key] = vals
+		}
+		r.AddCookie(cookies[0])
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := post(token, nil); code != http.StatusOK {
+		t.Errorf("valid token: expected status 200, but got %d", code)
+	}
+	if code := post("", nil); code != http.StatusForbidden {
+		t.Errorf("missing token: expected status 403, but got %d", code)
+	}
+	if code := post(token+"x", nil); code != http.StatusForbidden {
+		t.Errorf("invalid token: expected status 403, but got %d", code)
+	}
+	if code := post(token, http.Header{"Sec-Fetch-Site": {"cross-site"}}); code != http.StatusForbidden {
+		t.Errorf("cross site: expected status 403, but got %d", code)
+	}
+	if code := post("", http.Header{sxhttp.DefaultCSRFHeader: {token}}); code != http.StatusOK {
+		t.Errorf("token in header: expected status 200, but got %d", code)
+	}
+}
@@ -0,0 +1,250 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+)
+
+// PanicError is an error that was produced by a recovered panic.
+type PanicError struct {
+	Value any
+	Stack []byte // Go stack at the time of the panic
+}
+
+func (pe *PanicError) Error() string { return fmt.Sprintf("panic: %v", pe.Value) }
+
+// Unwrap returns the value of the panic, if it is an error.
+func (pe *PanicError) Unwrap() error {
+	if err, isError := pe.Value.(error); isError {
+		return err
+	}
+	return nil
+}
+
+// recoverPanic converts the value of recover() into an error. A
+// http.ErrAbortHandler is re-panicked, as intended by net/http.
+func recoverPanic(val any) error {
+	if val == http.ErrAbortHandler {
+		panic(val)
+	}
+	return &PanicError{Value: val, Stack: debug.Stack()}
+}
+
+// ErrorPages renders error responses as HTML.
+//
+// In development mode, a page with details about the error is shown: the
+// error message, the failing Sx expression, the Sx stack, the Go stack of a
+// panic, and details of the request. Otherwise, a page is rendered by a Sx
+// procedure (lambda (code r) ...) that was set for the status code, or a
+// plain text message is sent.
+//
+// The procedures are evaluated like those of a Handler: within a budget, and
+// a panic is reported as an error. Since an error page is often sent because
+// the request timed out, the budget does not depend on the context of the
+// request. If the page cannot be rendered, a plain text message is sent.
+//
+// A nil *ErrorPages sends plain text messages.
+type ErrorPages struct {
+	ev    evaluator
+	dev   bool
+	pages map[int]sxeval.Callable
+}
+
+// NewErrorPages creates a new renderer for error pages. The environment is
+// used to evaluate the procedures that render a page.
+func NewErrorPages(makeEnv EnvironmentMaker) *ErrorPages {
+	return &ErrorPages{ev: evaluator{makeEnv: makeEnv, logger: slog.Default()}, pages: map[int]sxeval.Callable{}}
+}
+
+// SetDevelopment enables the development mode.
+func (ep *ErrorPages) SetDevelopment(dev bool) *ErrorPages { ep.dev = dev; return ep }
+
+// defaultPageTimeout is the time to render a page, if the budget has no
+// timeout.
+const defaultPageTimeout = time.Second
+
+// SetBudget sets the evaluation budget for rendering a page. Without a
+// timeout, a page must be rendered within one second.
+func (ep *ErrorPages) SetBudget(budget Budget) *ErrorPages { ep.ev.budget = budget; return ep }
+
+// SetLogger sets the logger, where failures to render a page, and the panics
+// of Recover, are reported.
+func (ep *ErrorPages) SetLogger(logger *slog.Logger) *ErrorPages { ep.ev.logger = logger; return ep }
+
+// SetPage sets the Sx procedure (lambda (code r) ...) that returns the SxHTML
+// page for the given status code. Code 0 sets the page for all status codes
+// without an own page.
+func (ep *ErrorPages) SetPage(code int, proc sxeval.Callable) *ErrorPages {
+	ep.pages[code] = proc
+	return ep
+}
+
+// Write sends the error page for the status code and the error. Nothing is
+// sent, if the response was already started by a writer of this package,
+// e.g. of a Handler or of Recover.
+func (ep *ErrorPages) Write(w http.ResponseWriter, r *http.Request, code int, err error) {
+	if responseStarted(w) {
+		return
+	}
+	if ep == nil {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	var page sx.Object
+	var errPage error
+	if ep.dev {
+		page = DevelopmentPage(r, code, err)
+	} else {
+		page, errPage = ep.customPage(r, code)
+	}
+	var buf bytes.Buffer
+	if errPage == nil && !sx.IsNil(page) {
+		errPage = sxhtml.NewGenerator().WriteHTML(&buf, page)
+	}
+	if errPage != nil {
+		requestLogger(r.Context(), ep.ev.logger).Error("error page failed", "status", code, "error", errPage)
+	}
+	if errPage != nil || buf.Len() == 0 {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", MediaHTML+"; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
+
+func (ep *ErrorPages) customPage(r *http.Request, code int) (sx.Object, error) {
+	proc, found := ep.pages[code]
+	if !found {
+		if proc, found = ep.pages[0]; !found {
+			return sx.Nil(), nil
+		}
+	}
+	timeout := ep.ev.budget.Timeout
+	if timeout <= 0 {
+		timeout = defaultPageTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+	return ep.ev.call(r, proc, sx.Vector{sx.Int64(code), MakeRequest(r)})
+}
+
+// logger returns the logger, where errors are reported.
+func (ep *ErrorPages) logger() *slog.Logger {
+	if ep == nil {
+		return slog.Default()
+	}
+	return ep.ev.logger
+}
+
+// DevelopmentPage returns a SxHTML page that describes the error in detail.
+// It should never be sent in production, because it reveals internal data.
+func DevelopmentPage(r *http.Request, code int, err error) *sx.Pair {
+	title := strconv.Itoa(code) + " " + http.StatusText(code)
+	var lb sx.ListBuilder
+	lb.Add(htmlElem("h1", sx.MakeString(title)))
+	if err != nil {
+		lb.Add(htmlElem("h2", sx.MakeString("Error")))
+		lb.Add(htmlElem("pre", sx.MakeString(err.Error())))
+	}
+	if ee, isEval := errors.AsType[*EvalError](err); isEval && len(ee.Trace) > 0 {
+		lb.Add(htmlElem("h2", sx.MakeString("Failing expression")))
+		lb.Add(htmlElem("pre", sx.MakeString(ee.Trace[0])))
+		var items sx.ListBuilder
+		items.Add(sxhtml.MakeSymbol("ol"))
+		for _, expr := range ee.Trace {
+			items.Add(htmlElem("li", htmlElem("code", sx.MakeString(expr))))
+		}
+		lb.Add(htmlElem("h2", sx.MakeString("Sx stack")))
+		lb.Add(items.List())
+	}
+	if pe, isPanic := errors.AsType[*PanicError](err); isPanic {
+		lb.Add(htmlElem("h2", sx.MakeString("Go stack")))
+		lb.Add(htmlElem("pre", sx.MakeString(string(pe.Stack))))
+	}
+	lb.Add(htmlElem("h2", sx.MakeString("Request")))
+	lb.Add(requestTable(r))
+
+	return sx.MakeList(
+		sxhtml.SymDoctype,
+		htmlElem("html",
+			htmlElem("head", htmlElem("title", sx.MakeString(title))),
+			sx.Cons(sxhtml.MakeSymbol("body"), lb.List()),
+		),
+	)
+}
+
+func requestTable(r *http.Request) *sx.Pair {
+	var rows sx.ListBuilder
+	rows.Add(sxhtml.MakeSymbol("table"))
+	addRow := func(key, val string) {
+		rows.Add(htmlElem("tr", htmlElem("th", sx.MakeString(key)), htmlElem("td", sx.MakeString(val))))
+	}
+	addRow("Method", r.Method)
+	addRow("URL", r.URL.String())
+	addRow("Protocol", r.Proto)
+	addRow("Remote address", r.RemoteAddr)
+	for _, key := range slices.Sorted(maps.Keys(r.Header)) {
+		switch key {
+		case "Authorization", "Cookie", "Proxy-Authorization":
+			addRow(key, "[redacted]")
+		default:
+			for _, val := range r.Header.Values(key) {
+				addRow(key, val)
+			}
+		}
+	}
+	return rows.List()
+}
+
+func htmlElem(tag string, content ...sx.Object) *sx.Pair {
+	return sx.Cons(sxhtml.MakeSymbol(tag), sx.MakeList(content...))
+}
+
+// Recover returns a http.Handler that converts a panic of the next handler
+// into an error page with status 500. If the response was already started,
+// the panic is only logged.
+func Recover(next http.Handler, ep *ErrorPages) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			if val := recover(); val != nil {
+				err := recoverPanic(val)
+				requestLogger(r.Context(), ep.logger()).Error("handler panicked", "method", r.Method, "path", r.URL.Path, "error", err)
+				ep.Write(tw, r, http.StatusInternalServerError, err)
+			}
+		}()
+		next.ServeHTTP(tw, r)
+	})
+}
@@ -0,0 +1,172 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestDevelopmentPage(t *testing.T) {
+	t.Parallel()
+	ep := sxhttp.NewErrorPages(nil).SetDevelopment(true)
+	r := httptest.NewRequest("GET", "/fail?x=1", nil)
+	r.Header.Set("Cookie", "session=secret")
+	w := httptest.NewRecorder()
+	err := &sxhttp.EvalError{Err: errors.New("car: not a pair"), Trace: []string{"(car 1)", "(f 1)"}}
+	ep.Write(w, r, http.StatusInternalServerError, err)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, but got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("expected HTML, but got %q", got)
+	}
+	body := w.Body.String()
+	for _, exp := range []string{
+		"<!DOCTYPE html>",
+		"<title>500 Internal Server Error</title>",
+		"<pre>car: not a pair</pre>",
+		"<h2>Failing expression</h2><pre>(car 1)</pre>",
+		"<li><code>(f 1)</code></li>",
+		"<td>/fail?x=1</td>",
+		"<td>[redacted]</td>",
+	} {
+		if !strings.Contains(body, exp) {
+			t.Errorf("%q not found in %q", exp, body)
+		}
+	}
+	if strings.Contains(body, "secret") {
+		t.Error("cookie value must not be shown")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+	h := func(w http.ResponseWriter, r *http.Request) { panic("boom") }
+	testcases := []struct {
+		name  string
+		ep    *sxhttp.ErrorPages
+		ctype string
+		body  string
+	}{
+		{"plain", nil, "text/plain", "Internal Server Error"},
+		{"dev", sxhttp.NewErrorPages(nil).SetDevelopment(true), "text/html", "<h2>Go stack</h2>"},
+		{"no-page", sxhttp.NewErrorPages(nil), "text/plain", "Internal Server Error"},
+		{"page-failed", sxhttp.NewErrorPages(func(*http.Request) (*sxeval.Environment, error) {
+			return nil, errors.New("no environment")
+		}).SetPage(0, &sxhttp.Method), "text/plain", "Internal Server Error"},
+		{"page-panics", sxhttp.NewErrorPages(func(*http.Request) (*sxeval.Environment, error) {
+			panic("boom")
+		}).SetPage(0, &sxhttp.Method), "text/plain", "Internal Server Error"},
+		{"page-deadline", sxhttp.NewErrorPages(func(r *http.Request) (*sxeval.Environment, error) {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		}).SetPage(0, &sxhttp.Method).SetBudget(sxhttp.Budget{Timeout: 10 * time.Millisecond}), "text/plain", "Internal Server Error"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sxhttp.Recover(http.HandlerFunc(h), tc.ep).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("expected status 500, but got %d", w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tc.ctype) {
+				t.Errorf("expected content type %q, but got %q", tc.ctype, got)
+			}
+			if got := w.Body.String(); !strings.Contains(got, tc.body) {
+				t.Errorf("%q not found in %q", tc.body, got)
+			}
+		})
+	}
+}
+
+func TestRecoverLogger(t *testing.T) {
+	t.Parallel()
+	var logBuf strings.Builder
+	ep := sxhttp.NewErrorPages(nil).SetLogger(slog.New(slog.NewTextHandler(&logBuf, nil)))
+	h := func(http.ResponseWriter, *http.Request) { panic("boom") }
+	sxhttp.Recover(http.HandlerFunc(h), ep).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got := logBuf.String(); !strings.Contains(got, "handler panicked") {
+		t.Errorf("panic not logged by the logger of the error pages: %q", got)
+	}
+}
+
+func TestErrorPageAfterTimeout(t *testing.T) {
+	t.Parallel()
+	page := &sxeval.Builtin{
+		Name:     "timeout-page",
+		MinArity: 2,
+		MaxArity: 2,
+		Fn: func(*sxeval.Environment, sx.Vector, *sxeval.Frame) (sx.Object, error) {
+			return sx.MakeList(sxhtml.MakeSymbol("p"), sx.MakeString("Too slow")), nil
+		},
+	}
+	ep := sxhttp.NewErrorPages(func(*http.Request) (*sxeval.Environment, error) {
+		return sxeval.MakeEnvironment(sxeval.MakeRootBinding(1)), nil
+	}).SetPage(http.StatusGatewayTimeout, page)
+	h := sxhttp.NewHandler(func(r *http.Request) (*sxeval.Environment, error) {
+		<-r.Context().Done()
+		return nil, errors.New("no environment")
+	}, &sxhttp.Method).
+		SetBudget(sxhttp.Budget{Timeout: 10 * time.Millisecond}).
+		SetErrorPages(ep).
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status 504, but got %d", w.Code)
+	}
+	if got := w.Body.String(); got != "<p>Too slow</p>" {
+		t.Errorf("expected the custom page, but got %q", got)
+	}
+}
+
+func TestRecoverStarted(t *testing.T) {
+	t.Parallel()
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	})
+	w := httptest.NewRecorder()
+	sxhttp.Recover(h, sxhttp.NewErrorPages(nil).SetDevelopment(true)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, but got %d", w.Code)
+	}
+	if got := w.Body.String(); got != "partial" {
+		t.Errorf("expected only the started response, but got %q", got)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	t.Parallel()
+	errBase := errors.New("base")
+	if pe := (&sxhttp.PanicError{Value: errBase}); !errors.Is(pe, errBase) {
+		t.Error("panic error must unwrap an error value")
+	}
+	if pe := (&sxhttp.PanicError{Value: 17}); pe.Error() != "panic: 17" {
+		t.Errorf("unexpected message %q", pe.Error())
+	}
+}
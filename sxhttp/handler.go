@@ -0,0 +1,299 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+)
+
+// EnvironmentMaker creates a new Sx environment to evaluate a request.
+type EnvironmentMaker func(*http.Request) (*sxeval.Environment, error)
+
+// Budget limits the evaluation of Sx code for one request. A zero value
+// means no limit.
+//
+// There is no limit on memory allocation: Go does not account allocations
+// per goroutine, and measuring the whole process would let one request fail
+// because of others. A single step may allocate an arbitrary amount of
+// memory, e.g. when a builtin creates a long list, so neither limit bounds
+// the memory that is used by a request.
+type Budget struct {
+	// Timeout is the maximum evaluation time. It is applied to the request
+	// context, so Sx code will see it as a deadline.
+	Timeout time.Duration
+
+	// MaxSteps is the maximum number of evaluation steps.
+	MaxSteps int
+}
+
+// ErrBudgetExceeded is returned, if the evaluation needs more steps than
+// allowed by the budget.
+var ErrBudgetExceeded = errors.New("evaluation budget exceeded")
+
+// EvalError is an error that occurred while evaluating Sx code for a request.
+type EvalError struct {
+	Err   error
+	Trace []string // Sx expressions that were executed, innermost first.
+}
+
+func (ee *EvalError) Error() string { return ee.Err.Error() }
+
+// Unwrap returns the error that was the reason for the evaluation error.
+func (ee *EvalError) Unwrap() error { return ee.Err }
+
+// Handler is a http.Handler that calls a Sx procedure (lambda (w r) ...) for
+// every request. The procedure is evaluated under the context of the request,
+// i.e. evaluation is aborted if the client disconnects.
+type Handler struct {
+	ev   evaluator
+	proc sxeval.Callable
+}
+
+// NewHandler creates a new handler for the given Sx procedure.
+func NewHandler(makeEnv EnvironmentMaker, proc sxeval.Callable) *Handler {
+	return &Handler{ev: evaluator{makeEnv: makeEnv, logger: slog.Default()}, proc: proc}
+}
+
+// SetBudget sets the evaluation budget for every request.
+func (h *Handler) SetBudget(budget Budget) *Handler { h.ev.budget = budget; return h }
+
+// SetLogger sets the logger, where errors are reported.
+func (h *Handler) SetLogger(logger *slog.Logger) *Handler { h.ev.logger = logger; return h }
+
+// SetErrorPages sets the renderer for error pages.
+func (h *Handler) SetErrorPages(ep *ErrorPages) *Handler { h.ev.errPages = ep; return h }
+
+// ServeHTTP calls the Sx procedure with the response writer and the request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.ev.serve(w, r, h.proc, nil)
+}
+
+// evaluator contains all data to evaluate Sx code for a request.
+type evaluator struct {
+	makeEnv  EnvironmentMaker
+	budget   Budget
+	logger   *slog.Logger
+	errPages *ErrorPages
+}
+
+// serve calls the procedure with (w r) and handles all errors. If next is not
+// nil, the procedure is called with (next w r), where next calls the given
+// handler.
+func (ev *evaluator) serve(w http.ResponseWriter, r *http.Request, proc sxeval.Callable, next http.Handler) {
+	r, cancel := ev.withTimeout(r)
+	defer cancel()
+	tw := &trackingWriter{ResponseWriter: w}
+	callArgs := make(sx.Vector, 0, 3)
+	if next != nil {
+		callArgs = append(callArgs, makeNextBuiltin(next, tw, r))
+	}
+	callArgs = append(callArgs, MakeResponseWriter(tw), MakeRequest(r))
+	if _, err := ev.call(r, proc, callArgs); err != nil {
+		ev.handleError(tw, r, err)
+	}
+}
+
+// withTimeout returns the request with the timeout of the budget applied to
+// its context.
+func (ev *evaluator) withTimeout(r *http.Request) (*http.Request, context.CancelFunc) {
+	if ev.budget.Timeout <= 0 {
+		return r, func() {}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), ev.budget.Timeout)
+	return r.WithContext(ctx), cancel
+}
+
+// call evaluates the procedure with the given arguments under the context of
+// the given request. A panic, even when creating the environment, is
+// returned as a PanicError.
+func (ev *evaluator) call(r *http.Request, proc sxeval.Callable, args sx.Vector) (obj sx.Object, err error) {
+	defer beginEval(r.Context())()
+	obs := budgetObserver{ctx: r.Context(), maxSteps: ev.budget.MaxSteps}
+	defer func() {
+		if val := recover(); val != nil {
+			obs.recordTrace()
+			obj, err = nil, &EvalError{Err: recoverPanic(val), Trace: obs.trace}
+		}
+	}()
+	env, err := ev.makeEnv(r)
+	if err != nil {
+		return nil, contextError(r.Context(), err)
+	}
+	env.SetExecutor(&obs)
+	obj, err = env.Call(proc, args)
+	if err != nil {
+		err = contextError(r.Context(), err)
+		if _, isEval := errors.AsType[*EvalError](err); isEval {
+			return obj, err
+		}
+		return obj, &EvalError{Err: err, Trace: obs.trace}
+	}
+	return obj, nil
+}
+
+// contextError adds the error of the context to a failed evaluation, if the
+// context is done. A builtin may fail because of a cancelled context without
+// returning its error, which is needed to determine the status code.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	return err
+}
+
+// handleError logs the error and sends an error page with an appropriate
+// status code. If the request is logged by LogRequests, its request-scoped
+// logger is used. If the response was already started, only the error is
+// logged.
+func (ev *evaluator) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	code := ErrorStatus(err)
+	attrs := []any{"method", r.Method, "path", r.URL.Path, "status", code, "error", err}
+	if ee, isEval := errors.AsType[*EvalError](err); isEval && len(ee.Trace) > 0 {
+		attrs = append(attrs, "trace", ee.Trace)
+	}
+	requestLogger(r.Context(), ev.logger).Error("sx evaluation failed", attrs...)
+	if errors.Is(err, context.Canceled) || responseStarted(w) {
+		return // Client went away, or already got a response
+	}
+	ev.errPages.Write(w, r, code, err)
+}
+
+// trackingWriter records, whether the response was started, i.e. whether the
+// status code was sent, or whether the connection was hijacked. After that,
+// no error page can be sent.
+type trackingWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (tw *trackingWriter) WriteHeader(code int) {
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		tw.started = true
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *trackingWriter) Write(p []byte) (int, error) {
+	tw.started = true
+	return tw.ResponseWriter.Write(p)
+}
+
+// FlushError flushes the response, which sends the status code.
+func (tw *trackingWriter) FlushError() error {
+	tw.started = true
+	return http.NewResponseController(tw.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection, e.g. for a WebSocket.
+func (tw *trackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(tw.ResponseWriter).Hijack()
+	if err == nil {
+		tw.started = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap returns the original response writer for http.ResponseController.
+func (tw *trackingWriter) Unwrap() http.ResponseWriter { return tw.ResponseWriter }
+
+// responseStarted returns true, if the response writer, or one of the
+// writers it wraps, is known to have started the response.
+func responseStarted(w http.ResponseWriter) bool {
+	for w != nil {
+		if tw, isTracking := w.(*trackingWriter); isTracking && tw.started {
+			return true
+		}
+		uw, isWrapper := w.(interface{ Unwrap() http.ResponseWriter })
+		if !isWrapper {
+			return false
+		}
+		w = uw.Unwrap()
+	}
+	return false
+}
+
+// ErrorStatus returns the HTTP status code for an evaluation error.
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrBudgetExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// budgetObserver checks the context and the number of steps before an
+// expression is executed. It records the expressions that are currently
+// executed, to produce a trace if an error occurs.
+type budgetObserver struct {
+	ctx      context.Context
+	steps    int
+	maxSteps int
+	stack    []sxeval.Expr
+	trace    []string
+}
+
+// checkInterval is the number of steps after which the context is checked.
+const checkInterval = 64
+
+// BeforeExecution pushes the expression before checking the budget, so that
+// AfterExecution always pops a matching entry, even if the check fails.
+func (bo *budgetObserver) BeforeExecution(_ *sxeval.Environment, expr sxeval.Expr) (sxeval.Expr, error) {
+	bo.steps++
+	bo.stack = append(bo.stack, expr)
+	if bo.maxSteps > 0 && bo.steps > bo.maxSteps {
+		bo.recordTrace()
+		return nil, ErrBudgetExceeded
+	}
+	if bo.steps%checkInterval == 0 {
+		if err := bo.ctx.Err(); err != nil {
+			bo.recordTrace()
+			return nil, err
+		}
+	}
+	return expr, nil
+}
+
+func (bo *budgetObserver) AfterExecution(_ *sxeval.Environment, _ sxeval.Expr, _ sx.Object, err error) {
+	if err != nil {
+		bo.recordTrace()
+	}
+	if n := len(bo.stack); n > 0 {
+		bo.stack = bo.stack[:n-1]
+	}
+}
+
+// recordTrace stores the current stack as a trace, if there was no trace
+// before.
+func (bo *budgetObserver) recordTrace() {
+	if bo.trace != nil {
+		return
+	}
+	trace := make([]string, 0, len(bo.stack))
+	for i := len(bo.stack) - 1; i >= 0; i-- {
+		trace = append(trace, fmt.Sprint(bo.stack[i]))
+	}
+	bo.trace = trace
+}
@@ -0,0 +1,131 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestHandlerPanic(t *testing.T) {
+	t.Parallel()
+	var logBuf strings.Builder
+	h := sxhttp.NewHandler(func(*http.Request) (*sxeval.Environment, error) {
+		panic("boom")
+	}, &sxhttp.Method).SetLogger(slog.New(slog.NewTextHandler(&logBuf, nil)))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, but got %d", w.Code)
+	}
+	if got := logBuf.String(); !strings.Contains(got, "panic: boom") {
+		t.Errorf("panic not logged: %q", got)
+	}
+}
+
+func TestHandlerDeadline(t *testing.T) {
+	t.Parallel()
+	h := sxhttp.NewHandler(func(r *http.Request) (*sxeval.Environment, error) {
+		<-r.Context().Done()
+		return nil, errors.New("no environment")
+	}, &sxhttp.Method).
+		SetBudget(sxhttp.Budget{Timeout: 10 * time.Millisecond}).
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status 504, but got %d", w.Code)
+	}
+}
+
+func TestHandlerEndlessRecursion(t *testing.T) {
+	t.Parallel()
+	bind := sxeval.MakeRootBinding(256)
+	if err := sxbuiltins.BindAll(bind); err != nil {
+		t.Fatal(err)
+	}
+	env := sxeval.MakeEnvironment(bind)
+	rd := sxreader.MakeReader(strings.NewReader("(defun f () (f)) (defun handle (w r) (f))"))
+	for {
+		obj, err := rd.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = env.Eval(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	obj, found := bind.Lookup(sx.MakeSymbol("handle"))
+	if !found {
+		t.Fatal("handler procedure not defined")
+	}
+	proc, isCallable := sxeval.GetCallable(obj)
+	if !isCallable {
+		t.Fatalf("handler is not a procedure: %v", obj)
+	}
+	makeEnv := func(*http.Request) (*sxeval.Environment, error) { return sxeval.MakeEnvironment(bind), nil }
+	testcases := []struct {
+		name   string
+		budget sxhttp.Budget
+		code   int
+	}{
+		{"steps", sxhttp.Budget{MaxSteps: 10000}, http.StatusServiceUnavailable},
+		{"timeout", sxhttp.Budget{Timeout: 20 * time.Millisecond}, http.StatusGatewayTimeout},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := sxhttp.NewHandler(makeEnv, proc).
+				SetBudget(tc.budget).
+				SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			if w.Code != tc.code {
+				t.Errorf("expected status %d, but got %d", tc.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		err  error
+		code int
+	}{
+		{errors.New("other"), http.StatusInternalServerError},
+		{&sxhttp.EvalError{Err: sxhttp.ErrBudgetExceeded}, http.StatusServiceUnavailable},
+		{&sxhttp.EvalError{Err: context.DeadlineExceeded}, http.StatusGatewayTimeout},
+	}
+	for _, tc := range testcases {
+		if got := sxhttp.ErrorStatus(tc.err); got != tc.code {
+			t.Errorf("%v: expected %d, but got %d", tc.err, tc.code, got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+)
+
+// requestLog contains the logging data of one request.
+type requestLog struct {
+	mx       sync.Mutex
+	logger   *slog.Logger
+	depth    int // nesting of Sx evaluations, e.g. by middlewares
+	start    time.Time
+	evalTime time.Duration
+}
+
+type requestLogKey struct{}
+
+// LogRequests returns a http.Handler that logs every request with the given
+// logger, after it was served by next. The log line contains the method,
+// path, status, number of bytes written, the duration, and the time needed to
+// evaluate Sx code.
+//
+// A request-scoped logger is stored in the request context. It contains an
+// unique request id, and all attributes that were added by Sx code, e.g. via
+// "log-info".
+func LogRequests(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rl := &requestLog{logger: logger.With("id", makeRequestID())}
+		lw := &logWriter{w: w}
+		next.ServeHTTP(lw, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, rl)))
+
+		status := lw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		rl.mx.Lock()
+		reqLogger, evalTime := rl.logger, rl.evalTime
+		rl.mx.Unlock()
+		reqLogger.Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", lw.bytes,
+			"duration", time.Since(start),
+			"eval", evalTime,
+		)
+	})
+}
+
+func makeRequestID() string {
+	var buf [8]byte
+	rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+// LoggerFrom returns the request-scoped logger of the given context. If there
+// is none, the default logger is returned.
+func LoggerFrom(ctx context.Context) *slog.Logger { return requestLogger(ctx, slog.Default()) }
+
+func requestLogger(ctx context.Context, defLogger *slog.Logger) *slog.Logger {
+	if rl, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		rl.mx.Lock()
+		defer rl.mx.Unlock()
+		return rl.logger
+	}
+	return defLogger
+}
+
+// beginEval records the start of a Sx evaluation. The returned function must
+// be called at the end of the evaluation. Only the outermost evaluation is
+// measured, because nested ones are part of it.
+func beginEval(ctx context.Context) func() {
+	rl, ok := ctx.Value(requestLogKey{}).(*requestLog)
+	if !ok {
+		return func() {}
+	}
+	rl.mx.Lock()
+	if rl.depth == 0 {
+		rl.start = time.Now()
+	}
+	rl.depth++
+	rl.mx.Unlock()
+	return func() {
+		rl.mx.Lock()
+		rl.depth--
+		if rl.depth == 0 {
+			rl.evalTime += time.Since(rl.start)
+		}
+		rl.mx.Unlock()
+	}
+}
+
+// logWriter records the status code and the number of bytes written.
+//
+// Informational status codes are not recorded, because the final status code
+// follows, except for 101 "Switching Protocols". A hijacked connection is
+// recorded with status 101 too, e.g. a WebSocket that was upgraded. The bytes
+// written to a hijacked connection are not counted.
+type logWriter struct {
+	w      http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (lw *logWriter) Header() http.Header { return lw.w.Header() }
+
+func (lw *logWriter) WriteHeader(code int) {
+	if lw.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		lw.status = code
+	}
+	lw.w.WriteHeader(code)
+}
+
+// Hijack lets the caller take over the connection, for
+// http.ResponseController.
+func (lw *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(lw.w).Hijack()
+	if err == nil && lw.status == 0 {
+		lw.status = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+func (lw *logWriter) Write(p []byte) (int, error) {
+	if lw.status == 0 {
+		lw.status = http.StatusOK
+	}
+	n, err := lw.w.Write(p)
+	lw.bytes += n
+	return n, err
+}
+
+// Unwrap returns the original response writer, for http.ResponseController.
+func (lw *logWriter) Unwrap() http.ResponseWriter { return lw.w }
+
+// ----- Builtins ------------------------------------------------------------
+
+// Builtins that log a message with the request-scoped logger:
+// (log-info r "msg" key val ...). The first argument is a request or a
+// context. The key/value pairs are added to the request-scoped logger, so
+// they are part of all following log lines of the request, including the one
+// written by LogRequests.
+var (
+	LogDebug = makeLogBuiltin("log-debug", slog.LevelDebug)
+	LogInfo  = makeLogBuiltin("log-info", slog.LevelInfo)
+	LogWarn  = makeLogBuiltin("log-warn", slog.LevelWarn)
+	LogError = makeLogBuiltin("log-error", slog.LevelError)
+)
+
+func makeLogBuiltin(name string, level slog.Level) sxeval.Builtin {
+	return sxeval.Builtin{
+		Name:     name,
+		MinArity: 2,
+		MaxArity: -1,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			var ctx context.Context
+			if r, isRequest := GetRequest(args[0]); isRequest {
+				ctx = r.GetValue().Context()
+			} else if c, isContext := GetContext(args[0]); isContext {
+				ctx = c.val
+			} else {
+				return sx.Nil(), fmt.Errorf("argument 1 is not a request or a context, but %T/%v", args[0], args[0])
+			}
+			msg, err := getBuiltinKey(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			if len(args)%2 != 0 {
+				return sx.Nil(), fmt.Errorf("%s: key %v without value", name, args[len(args)-1])
+			}
+			attrs := make([]any, 0, len(args)-2)
+			for i := 2; i < len(args); i += 2 {
+				key, errKey := getBuiltinKey(args[i], i)
+				if errKey != nil {
+					return sx.Nil(), errKey
+				}
+				attrs = append(attrs, slog.Any(key, logValue(args[i+1])))
+			}
+
+			logger := LoggerFrom(ctx)
+			if rl, ok := ctx.Value(requestLogKey{}).(*requestLog); ok && len(attrs) > 0 {
+				rl.mx.Lock()
+				rl.logger = rl.logger.With(attrs...)
+				logger = rl.logger
+				rl.mx.Unlock()
+			} else {
+				logger = logger.With(attrs...)
+			}
+			logger.Log(ctx, level, msg)
+			return sx.Nil(), nil
+		},
+	}
+}
+
+// logValue converts a Sx object into a value for slog.
+func logValue(obj sx.Object) any {
+	switch o := obj.(type) {
+	case sx.String:
+		return o.GetValue()
+	case *sx.Symbol:
+		return o.GetValue()
+	case sx.Int64:
+		return int64(o)
+	}
+	return obj.String()
+}
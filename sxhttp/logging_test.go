@@ -0,0 +1,115 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestLogRequests(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	h := sxhttp.LogRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		args := sx.Vector{sxhttp.MakeRequest(r), sx.MakeString("lookup"), sx.MakeSymbol("user"), sx.MakeString("detlef")}
+		if _, err := sxhttp.LogWarn.Fn(nil, args, nil); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "not found")
+	}), logger)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/17", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two log lines, but got %q", lines)
+	}
+	for _, exp := range []string{"level=WARN", "msg=lookup", "user=detlef", "id="} {
+		if !strings.Contains(lines[0], exp) {
+			t.Errorf("%q not found in first line %q", exp, lines[0])
+		}
+	}
+	for _, exp := range []string{"level=INFO", "msg=request", "user=detlef", "method=GET", "path=/user/17", "status=404", "bytes=9", "duration=", "eval="} {
+		if !strings.Contains(lines[1], exp) {
+			t.Errorf("%q not found in second line %q", exp, lines[1])
+		}
+	}
+	id := lines[0][strings.Index(lines[0], "id="):][:len("id=")+16]
+	if !strings.Contains(lines[1], id) {
+		t.Errorf("log lines are not correlated by %q", id)
+	}
+}
+
+func TestLogBuiltinErrors(t *testing.T) {
+	t.Parallel()
+	r := sxhttp.MakeRequest(httptest.NewRequest("GET", "/", nil))
+	testcases := []struct {
+		name string
+		args sx.Vector
+	}{
+		{"no-request", sx.Vector{sx.MakeString("x"), sx.MakeString("msg")}},
+		{"odd", sx.Vector{r, sx.MakeString("msg"), sx.MakeSymbol("key")}},
+		{"bad-key", sx.Vector{r, sx.MakeString("msg"), sx.Int64(1), sx.Int64(2)}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := sxhttp.LogInfo.Fn(nil, tc.args, nil); err == nil {
+				t.Error("error expected")
+			}
+		})
+	}
+}
+
+func TestLogRequestsInformational(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	h := sxhttp.LogRequests(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusEarlyHints)
+		io.WriteString(w, "ok")
+	}), slog.New(slog.NewTextHandler(&buf, nil)))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got := buf.String(); !strings.Contains(got, "status=200") || !strings.Contains(got, "bytes=2") {
+		t.Errorf("expected status 200 after early hints, but got %q", got)
+	}
+}
+
+func TestLogRequestsHijack(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	logged := sxhttp.LogRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := sxhttp.Upgrade(w, r); err != nil {
+			t.Error(err)
+		}
+	}), slog.New(slog.NewTextHandler(&buf, nil)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		logged.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	dialWebSocket(t, srv)
+	<-done
+	if got := buf.String(); !strings.Contains(got, "status=101") {
+		t.Errorf("expected status 101 for upgraded connection, but got %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+)
+
+// Middleware wraps a http.Handler with a Sx procedure (lambda (next w r) ...).
+//
+// The procedure is called for every request. The first argument "next" is a
+// callable object that invokes the downstream handler. It can be called as
+// (next), (next r), or (next w r), where missing arguments are the ones the
+// procedure was called with. In particular, the request carries the context
+// with the timeout of the budget. The Sx procedure may not call "next" at
+// all, e.g. if an authentication check fails.
+//
+// The procedure can be replaced while the middleware is in use, e.g. after
+// Sx source code was reloaded.
+type Middleware struct {
+	ev   evaluator
+	proc atomic.Pointer[middlewareProc]
+}
+
+type middlewareProc struct{ proc sxeval.Callable }
+
+// NewMiddleware creates a new middleware for the given Sx procedure.
+func NewMiddleware(makeEnv EnvironmentMaker, proc sxeval.Callable) *Middleware {
+	mw := &Middleware{ev: evaluator{makeEnv: makeEnv, logger: slog.Default()}}
+	mw.SetProcedure(proc)
+	return mw
+}
+
+// SetBudget sets the evaluation budget for every request.
+func (mw *Middleware) SetBudget(budget Budget) *Middleware { mw.ev.budget = budget; return mw }
+
+// SetLogger sets the logger, where errors are reported.
+func (mw *Middleware) SetLogger(logger *slog.Logger) *Middleware { mw.ev.logger = logger; return mw }
+
+// SetErrorPages sets the renderer for error pages.
+func (mw *Middleware) SetErrorPages(ep *ErrorPages) *Middleware { mw.ev.errPages = ep; return mw }
+
+// SetProcedure replaces the Sx procedure. Requests that are currently served
+// use the previous procedure.
+func (mw *Middleware) SetProcedure(proc sxeval.Callable) {
+	mw.proc.Store(&middlewareProc{proc})
+}
+
+// Wrap returns a http.Handler that calls the Sx procedure of the middleware,
+// with next as the downstream handler.
+func (mw *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mw.ev.serve(w, r, mw.proc.Load().proc, next)
+	})
+}
+
+// Chain wraps the given handler with all middlewares. The first middleware
+// is the outermost one, i.e. it is called first.
+func Chain(h http.Handler, mws ...*Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i].Wrap(h)
+	}
+	return h
+}
+
+// makeNextBuiltin creates the callable object that calls the next handler
+// with the given response writer and request, unless other ones are given.
+func makeNextBuiltin(next http.Handler, w http.ResponseWriter, r *http.Request) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "next",
+		MinArity: 0,
+		MaxArity: 2,
+		Fn0: func(*sxeval.Environment, *sxeval.Frame) (sx.Object, error) {
+			next.ServeHTTP(w, r)
+			return sx.Nil(), nil
+		},
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			nextReq, err := GetBuiltinRequest(arg, 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			next.ServeHTTP(w, nextReq.GetValue())
+			return sx.Nil(), nil
+		},
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			nextW, err := GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			nextReq, err := GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			next.ServeHTTP(nextW.GetValue(), nextReq.GetValue())
+			return sx.Nil(), nil
+		},
+	}
+}
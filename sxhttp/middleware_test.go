@@ -0,0 +1,118 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func makeTestEnv(*http.Request) (*sxeval.Environment, error) {
+	return sxeval.MakeEnvironment(sxeval.MakeRootBinding(16)), nil
+}
+
+// nextCaller calls the "next" builtin, that was given to a middleware.
+type nextCaller func(*sxeval.Environment, *sxeval.Builtin, sx.Vector) (sx.Object, error)
+
+// makeMiddlewareProc returns a procedure (lambda (next w r) ...) that appends
+// name to the header "X-Order" and then calls next as given by callNext. If
+// callNext is nil, next is not called.
+func makeMiddlewareProc(name string, callNext nextCaller) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "mw-" + name,
+		MinArity: 3,
+		MaxArity: 3,
+		Fn: func(env *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := sxhttp.GetBuiltinResponseWriter(args[1], 1)
+			if err != nil {
+				return nil, err
+			}
+			w.GetValue().Header().Add("X-Order", name)
+			if callNext == nil {
+				w.GetValue().WriteHeader(http.StatusForbidden)
+				return sx.Nil(), nil
+			}
+			return callNext(env, args[0].(*sxeval.Builtin), args)
+		},
+	}
+}
+
+func callNext0(env *sxeval.Environment, next *sxeval.Builtin, _ sx.Vector) (sx.Object, error) {
+	return next.Fn0(env, nil)
+}
+
+func callNext1(env *sxeval.Environment, next *sxeval.Builtin, args sx.Vector) (sx.Object, error) {
+	return next.Fn1(env, args[2], nil)
+}
+
+func callNext2(env *sxeval.Environment, next *sxeval.Builtin, args sx.Vector) (sx.Object, error) {
+	return next.Fn(env, args[1:], nil)
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name     string
+		callNext []nextCaller
+		status   int
+		order    []string
+		reached  bool
+	}{
+		{"next", []nextCaller{callNext0}, http.StatusOK, []string{"0"}, true},
+		{"next-r", []nextCaller{callNext1}, http.StatusOK, []string{"0"}, true},
+		{"next-w-r", []nextCaller{callNext2}, http.StatusOK, []string{"0"}, true},
+		{"order", []nextCaller{callNext0, callNext1, callNext2}, http.StatusOK, []string{"0", "1", "2"}, true},
+		{"short-circuit", []nextCaller{callNext0, nil, callNext0}, http.StatusForbidden, []string{"0", "1"}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			reached, hasDeadline := false, false
+			final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+				_, hasDeadline = r.Context().Deadline()
+				w.WriteHeader(http.StatusOK)
+			})
+			mws := make([]*sxhttp.Middleware, len(tc.callNext))
+			for i, callNext := range tc.callNext {
+				mws[i] = sxhttp.NewMiddleware(makeTestEnv, makeMiddlewareProc(string(rune('0'+i)), callNext)).
+					SetBudget(sxhttp.Budget{Timeout: time.Minute}).
+					SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			}
+			w := httptest.NewRecorder()
+			sxhttp.Chain(final, mws...).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			if w.Code != tc.status {
+				t.Errorf("expected status %d, but got %d", tc.status, w.Code)
+			}
+			if got := w.Header().Values("X-Order"); !slices.Equal(got, tc.order) {
+				t.Errorf("expected order %v, but got %v", tc.order, got)
+			}
+			if reached != tc.reached {
+				t.Errorf("expected handler reached %v, but got %v", tc.reached, reached)
+			}
+			if reached && !hasDeadline {
+				t.Error("next was not called with the request of the budget")
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+)
+
+// Media types supported by WriteNegotiated.
+const (
+	MediaHTML  = "text/html"
+	MediaJSON  = "application/json"
+	MediaSexpr = "application/x-sexpr"
+)
+
+// MediaRange is one element of an Accept header.
+type MediaRange struct {
+	Type    string // main type, e.g. "text", or "*"
+	Subtype string // sub type, e.g. "html", or "*"
+	Params  int    // number of parameters, except "q"
+	Q       float64
+}
+
+// ParseAccept parses the value of an Accept header. Invalid elements are
+// ignored.
+func ParseAccept(accept string) []MediaRange {
+	var result []MediaRange
+	for elem := range strings.SplitSeq(accept, ",") {
+		params := strings.Split(elem, ";")
+		typ, subtype, found := strings.Cut(strings.TrimSpace(params[0]), "/")
+		if !found || typ == "" || subtype == "" {
+			continue
+		}
+		mr := MediaRange{Type: strings.ToLower(typ), Subtype: strings.ToLower(subtype), Q: 1}
+		for _, param := range params[1:] {
+			key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil && q >= 0 && q <= 1 {
+					mr.Q = q
+				}
+			} else {
+				mr.Params++
+			}
+		}
+		result = append(result, mr)
+	}
+	return result
+}
+
+// specificity returns how specific the media range matches the media type, or
+// -1 if it does not match.
+func (mr MediaRange) specificity(typ, subtype string) int {
+	switch {
+	case mr.Type == "*":
+		return 0
+	case mr.Type != typ:
+		return -1
+	case mr.Subtype == "*":
+		return 1
+	case mr.Subtype != subtype:
+		return -1
+	}
+	return 2 + mr.Params
+}
+
+// Negotiate returns the offered media type that is preferred by the given
+// Accept header. If the header is empty, the first offer is returned. If no
+// offer is acceptable, the empty string is returned. For offers with equal
+// quality, the first one wins.
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := ParseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		typ, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+		spec, q := -1, 0.0
+		for _, mr := range ranges {
+			if s := mr.specificity(typ, subtype); s > spec {
+				spec, q = s, mr.Q
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// Negotiation is a builtin that returns the offered media type, that is
+// preferred by the request, or nil if no offer is acceptable:
+// (request-negotiate r "text/html" "application/json" ...).
+var Negotiation = sxeval.Builtin{
+	Name:     "request-negotiate",
+	MinArity: 2,
+	MaxArity: -1,
+	TestPure: sxeval.AssertPure,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		r, err := GetBuiltinRequest(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		offers := make([]string, 0, len(args)-1)
+		for i := 1; i < len(args); i++ {
+			s, errString := sxbuiltins.GetString(args[i], i)
+			if errString != nil {
+				return sx.Nil(), errString
+			}
+			offers = append(offers, s.GetValue())
+		}
+		if result := Negotiate(r.GetValue().Header.Get("Accept"), offers...); result != "" {
+			return sx.MakeString(result), nil
+		}
+		return sx.Nil(), nil
+	},
+}
+
+// WriteNegotiated writes the Sx object in the format preferred by the
+// request: as HTML (generated by the given generator), as JSON, or as a
+// printed s-expression. If no format is acceptable, status 406 is sent.
+func WriteNegotiated(w http.ResponseWriter, r *http.Request, gen *sxhtml.Generator, obj sx.Object) error {
+	w.Header().Add("Vary", "Accept")
+	switch Negotiate(r.Header.Get("Accept"), MediaHTML, MediaJSON, MediaSexpr) {
+	case MediaHTML:
+		w.Header().Set("Content-Type", MediaHTML+"; charset=utf-8")
+		return gen.WriteHTML(w, obj)
+	case MediaJSON:
+		val, err := ToJSON(obj)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", MediaJSON+"; charset=utf-8")
+		return json.NewEncoder(w).Encode(val)
+	case MediaSexpr:
+		w.Header().Set("Content-Type", MediaSexpr+"; charset=utf-8")
+		_, err := io.WriteString(w, obj.String()+"\n")
+		return err
+	}
+	http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	return nil
+}
+
+// ToJSON converts a Sx object into a value that can be encoded as JSON.
+//
+// Nil becomes null, strings and symbols become JSON strings, and numbers
+// become JSON numbers. An association list, where every element is a pair
+// with a unique string or symbol key, becomes a JSON object. The value of a
+// key is the converted cdr of its pair, so (a . 1) has the value 1, (a 1 2)
+// has the value [1,2], and (a (b . 1)) has the value {"b":1}. All other lists
+// and vectors become JSON arrays.
+func ToJSON(obj sx.Object) (any, error) {
+	if sx.IsNil(obj) {
+		return nil, nil
+	}
+	switch o := obj.(type) {
+	case sx.String:
+		return o.GetValue(), nil
+	case *sx.Symbol:
+		return o.GetValue(), nil
+	case sx.Number:
+		return json.Number(o.String()), nil
+	case *sx.Pair:
+		if m, isAlist, err := alistToJSON(o); isAlist {
+			return m, err
+		}
+		return sequenceToJSON(o.Values())
+	case sx.Vector:
+		return sequenceToJSON(slices.Values(o))
+	}
+	return nil, fmt.Errorf("cannot convert to JSON: %T/%v", obj, obj)
+}
+
+func alistToJSON(lst *sx.Pair) (map[string]any, bool, error) {
+	keys := map[string]struct{}{}
+	for elem := range lst.Values() {
+		pair, isPair := sx.GetPair(elem)
+		if !isPair || pair == nil {
+			return nil, false, nil
+		}
+		key := jsonKey(pair.Car())
+		if key == "" {
+			return nil, false, nil
+		}
+		if _, found := keys[key]; found {
+			return nil, false, nil
+		}
+		keys[key] = struct{}{}
+	}
+	m := make(map[string]any, len(keys))
+	for elem := range lst.Values() {
+		pair, _ := sx.GetPair(elem)
+		val, err := ToJSON(pair.Cdr())
+		if err != nil {
+			return nil, true, err
+		}
+		m[jsonKey(pair.Car())] = val
+	}
+	return m, true, nil
+}
+
+func jsonKey(obj sx.Object) string {
+	switch o := obj.(type) {
+	case sx.String:
+		return o.GetValue()
+	case *sx.Symbol:
+		return o.GetValue()
+	}
+	return ""
+}
+
+func sequenceToJSON(seq iter.Seq[sx.Object]) ([]any, error) {
+	result := []any{}
+	for elem := range seq {
+		val, err := ToJSON(elem)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+// ParseJSON reads one JSON value and converts it into a Sx object, using
+// FromJSON.
+func ParseJSON(r io.Reader) (sx.Object, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return sx.Nil(), err
+	}
+	return FromJSON(val), nil
+}
+
+// FromJSON converts a decoded JSON value into a Sx object: null becomes nil,
+// objects become association lists with symbol keys (sorted by key), and
+// arrays become lists. Integer numbers become integers, all other numbers
+// become strings.
+//
+// ToJSON reverts this conversion for objects with non-empty values, and for
+// arrays of strings, integers, and objects. It is not an exact inverse:
+// other numbers are converted back to strings, empty objects and arrays to
+// null, and an array of arrays that all start with a distinct string becomes
+// an object.
+func FromJSON(val any) sx.Object {
+	switch v := val.(type) {
+	case string:
+		return sx.MakeString(v)
+	case bool:
+		return sx.MakeBoolean(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return sx.Int64(i)
+		}
+		return sx.MakeString(v.String())
+	case float64:
+		if i := int64(v); float64(i) == v {
+			return sx.Int64(i)
+		}
+		return sx.MakeString(strconv.FormatFloat(v, 'g', -1, 64))
+	case []any:
+		var lb sx.ListBuilder
+		for _, elem := range v {
+			lb.Add(FromJSON(elem))
+		}
+		return lb.List()
+	case map[string]any:
+		var lb sx.ListBuilder
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			lb.Add(sx.Cons(sx.MakeSymbol(key), FromJSON(v[key])))
+		}
+		return lb.List()
+	}
+	return sx.Nil()
+}
+
+// MakeWriteNegotiatedBuiltin returns a builtin that provides the
+// (response-write-negotiated w r obj) function. For HTML output, a generator
+// is created for every request, e.g. to add an element hook.
+func MakeWriteNegotiatedBuiltin(makeGen func(*http.Request) *sxhtml.Generator) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "response-write-negotiated",
+		MinArity: 3,
+		MaxArity: 3,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			r, err := GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			req := r.GetValue()
+			return sx.Nil(), WriteNegotiated(w.GetValue(), req, makeGen(req), args[2])
+		},
+	}
+}
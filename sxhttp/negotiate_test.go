@@ -0,0 +1,156 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+	offers := []string{sxhttp.MediaHTML, sxhttp.MediaJSON, sxhttp.MediaSexpr}
+	testcases := []struct {
+		accept string
+		exp    string
+	}{
+		{"", sxhttp.MediaHTML},
+		{"*/*", sxhttp.MediaHTML},
+		{"application/json", sxhttp.MediaJSON},
+		{"application/*", sxhttp.MediaJSON},
+		{"text/html;q=0.5, application/json", sxhttp.MediaJSON},
+		{"text/html;q=0.5, application/x-sexpr;q=0.8, */*;q=0.1", sxhttp.MediaSexpr},
+		{"text/*;q=0.1, text/html;q=0, */*;q=0.2", sxhttp.MediaJSON},
+		{"image/png", ""},
+		{"text/html;q=0", ""},
+		{"APPLICATION/JSON", sxhttp.MediaJSON},
+		{"invalid, application/json;level=1", sxhttp.MediaJSON},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.accept, func(t *testing.T) {
+			if got := sxhttp.Negotiate(tc.accept, offers...); got != tc.exp {
+				t.Errorf("expected %q, but got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestWriteNegotiated(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		accept string
+		src    string
+		exp    string
+	}{
+		{"text/html", `(p "a")`, "<p>a</p>"},
+		{"application/x-sexpr", `(p "a")`, "(p \"a\")\n"},
+		{"application/json", `(p "a")`, `["p","a"]`},
+		{"application/json", `((a . 1) (b . "2"))`, `{"a":1,"b":"2"}`},
+		{"application/json", `((a 1) (b 2))`, `{"a":[1],"b":[2]}`},
+		{"application/json", `((a 1 2) (b (c . 3) (d "x" "y")))`, `{"a":[1,2],"b":{"c":3,"d":["x","y"]}}`},
+		{"application/json", `((li "a") (li "b"))`, `[["li","a"],["li","b"]]`},
+		{"application/json", `()`, `null`},
+		{"image/png", `(p "a")`, "Not Acceptable\n"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.accept+tc.src, func(t *testing.T) {
+			obj, err := sxreader.MakeReader(strings.NewReader(tc.src)).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+			if err = sxhttp.WriteNegotiated(w, r, sxhtml.NewGenerator(), obj); err != nil {
+				t.Fatal(err)
+			}
+			got := w.Body.String()
+			if tc.accept == sxhttp.MediaJSON {
+				if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+					t.Errorf("unexpected content type %q", ct)
+				}
+				var exp, val any
+				if err = json.Unmarshal([]byte(tc.exp), &exp); err != nil {
+					t.Fatal(err)
+				}
+				if err = json.Unmarshal([]byte(got), &val); err != nil {
+					t.Fatal(err)
+				}
+				expS, _ := json.Marshal(exp)
+				gotS, _ := json.Marshal(val)
+				if string(expS) != string(gotS) {
+					t.Errorf("expected %s, but got %s", expS, gotS)
+				}
+				return
+			}
+			if got != tc.exp {
+				t.Errorf("expected %q, but got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	t.Parallel()
+	obj, err := sxhttp.ParseJSON(strings.NewReader(`{"b": [1, 2.5], "a": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `((a . "x") (b 1 "2.5"))`; obj.String() != exp {
+		t.Errorf("expected %s, but got %s", exp, obj)
+	}
+}
+
+func TestJSONRoundtrip(t *testing.T) {
+	t.Parallel()
+	testcases := []string{
+		`null`,
+		`"x"`,
+		`42`,
+		`["a", 1, "b"]`,
+		`{"a": "x", "b": 1}`,
+		`{"a": [1, 2], "b": {"c": "x", "d": [{"e": 3}]}}`,
+		`[{"a": 1}, {"a": 2}]`,
+	}
+	for _, tc := range testcases {
+		t.Run(tc, func(t *testing.T) {
+			obj, err := sxhttp.ParseJSON(strings.NewReader(tc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			val, err := sxhttp.ToJSON(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var exp any
+			if err = json.Unmarshal([]byte(tc), &exp); err != nil {
+				t.Fatal(err)
+			}
+			expS, _ := json.Marshal(exp)
+			if string(got) != string(expS) {
+				t.Errorf("expected %s, but got %s (via %v)", expS, got, obj)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+)
+
+// ProcedureResolver returns the Sx procedure for a symbol.
+type ProcedureResolver func(*sx.Symbol) (sxeval.Callable, bool)
+
+// BindingResolver returns a resolver that looks up symbols in the given
+// binding.
+func BindingResolver(bind *sxeval.Binding) ProcedureResolver {
+	return func(sym *sx.Symbol) (sxeval.Callable, bool) {
+		obj, found := bind.Lookup(sym)
+		if !found {
+			return nil, false
+		}
+		return sxeval.GetCallable(obj)
+	}
+}
+
+// Routes registers routes, specified as Sx data, on a http.ServeMux.
+//
+// A route specification is a list of routes. Each route is a list
+// (METHOD "pattern" handler), where METHOD is a symbol like GET or POST,
+// "pattern" is a path pattern of http.ServeMux, and handler is a symbol that
+// names a Sx procedure (lambda (w r) ...). The method ANY matches all methods.
+// Wildcards of the pattern are available through "request-path-value".
+//
+//	((GET "/users/{id}" user-page) (POST "/users" create-user))
+type Routes struct {
+	ev       evaluator
+	resolve  ProcedureResolver
+	mx       sync.Mutex                  // protects patterns
+	patterns map[*http.ServeMux][]string // registered patterns, to detect conflicts early
+}
+
+// NewRoutes creates a new route registry.
+func NewRoutes(makeEnv EnvironmentMaker, resolve ProcedureResolver) *Routes {
+	return &Routes{
+		ev:       evaluator{makeEnv: makeEnv, logger: slog.Default()},
+		resolve:  resolve,
+		patterns: map[*http.ServeMux][]string{},
+	}
+}
+
+// SetBudget sets the evaluation budget for every request.
+func (rt *Routes) SetBudget(budget Budget) *Routes { rt.ev.budget = budget; return rt }
+
+// SetLogger sets the logger, where errors are reported.
+func (rt *Routes) SetLogger(logger *slog.Logger) *Routes { rt.ev.logger = logger; return rt }
+
+// SetErrorPages sets the renderer for error pages.
+func (rt *Routes) SetErrorPages(ep *ErrorPages) *Routes { rt.ev.errPages = ep; return rt }
+
+// RouteError is returned if a route specification is invalid, or if a route
+// conflicts with another one.
+type RouteError struct {
+	Pos    int       // Position of the route within the specification, starting with 1.
+	Source string    // Source position "name:line:column" of the route, if read by RegisterSource.
+	Route  sx.Object // The route itself.
+	Err    error
+}
+
+func (re *RouteError) Error() string {
+	if re.Source != "" {
+		return fmt.Sprintf("%s: route %d %v: %v", re.Source, re.Pos, re.Route, re.Err)
+	}
+	return fmt.Sprintf("route %d %v: %v", re.Pos, re.Route, re.Err)
+}
+
+// Unwrap returns the reason for the route error.
+func (re *RouteError) Unwrap() error { return re.Err }
+
+// Register all routes of the specification on the given mux. All routes are
+// validated before the first one is registered, so that an invalid or
+// conflicting route leaves the mux unchanged. Only conflicts with patterns
+// that were registered on the mux by other means are detected during
+// registration.
+func (rt *Routes) Register(mux *http.ServeMux, spec *sx.Pair) error {
+	return rt.register(mux, spec, nil)
+}
+
+// RegisterSource reads the route specification from src and registers it on
+// the given mux, like Register. Errors contain the source position of the
+// route, where name is typically the file name of the specification.
+func (rt *Routes) RegisterSource(mux *http.ServeMux, name string, src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+	obj, err := readFirst(text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	spec, isPair := sx.GetPair(obj)
+	if !isPair {
+		return fmt.Errorf("%s: route specification must be a list, but got %T/%v", name, obj, obj)
+	}
+	offsets := elementOffsets(text, spec)
+	return rt.register(mux, spec, func(pos int) string {
+		if pos > len(offsets) {
+			return name
+		}
+		line, col := lineColumn(text, offsets[pos-1])
+		return fmt.Sprintf("%s:%d:%d", name, line, col)
+	})
+}
+
+func (rt *Routes) register(mux *http.ServeMux, spec *sx.Pair, source func(int) string) error {
+	makeError := func(pos int, route sx.Object, err error) error {
+		re := &RouteError{Pos: pos, Route: route, Err: err}
+		if source != nil {
+			re.Source = source(pos)
+		}
+		return re
+	}
+	type parsedRoute struct {
+		route   sx.Object
+		pattern string
+		proc    sxeval.Callable
+	}
+	var routes []parsedRoute
+	check := rt.checkMux(mux)
+	for route := range spec.Values() {
+		pattern, proc, err := rt.parseRoute(route)
+		if err == nil {
+			err = HandleMux(check, pattern, http.NotFoundHandler())
+		}
+		if err != nil {
+			return makeError(len(routes)+1, route, err)
+		}
+		routes = append(routes, parsedRoute{route, pattern, proc})
+	}
+	for i, pr := range routes {
+		if err := rt.Handle(mux, pr.pattern, pr.proc); err != nil {
+			return makeError(i+1, pr.route, err)
+		}
+	}
+	return nil
+}
+
+// Route is a pattern together with the Sx procedure (lambda (w r) ...) that
+// handles it.
+type Route struct {
+	Pattern string
+	Proc    sxeval.Callable
+}
+
+// HandleAll registers all routes on the given mux. As with Register, all
+// patterns are validated before the first one is registered. A RouteError
+// refers to the position of the route within routes.
+func (rt *Routes) HandleAll(mux *http.ServeMux, routes []Route) error {
+	check := rt.checkMux(mux)
+	for i, route := range routes {
+		if err := HandleMux(check, route.Pattern, http.NotFoundHandler()); err != nil {
+			return &RouteError{Pos: i + 1, Route: sx.MakeString(route.Pattern), Err: err}
+		}
+	}
+	for i, route := range routes {
+		if err := rt.Handle(mux, route.Pattern, route.Proc); err != nil {
+			return &RouteError{Pos: i + 1, Route: sx.MakeString(route.Pattern), Err: err}
+		}
+	}
+	return nil
+}
+
+// checkMux returns a new mux with all patterns that were registered on the
+// given mux, to validate patterns without changing it.
+func (rt *Routes) checkMux(mux *http.ServeMux) *http.ServeMux {
+	check := http.NewServeMux()
+	for _, pattern := range rt.registered(mux) {
+		check.Handle(pattern, http.NotFoundHandler())
+	}
+	return check
+}
+
+// elementOffsets returns the byte offsets of all elements of spec, which was
+// read as the first form of text. The offsets are determined by the reader
+// itself, so that comments, reader macros, and escapes are treated exactly as
+// when reading the specification:
+//
+//   - The end of an element is the shortest text after the end of the previous
+//     element that reads as the element.
+//   - Its start is the latest offset, from which the text up to its end still
+//     reads as the element.
+//
+// Since every element is read repeatedly, this is quadratic in the length of
+// an element, which is fine for route specifications.
+func elementOffsets(text string, spec *sx.Pair) []int {
+	// The list starts after the shortest prefix that reads as an empty list
+	// when closed.
+	prev := shortestRead(text, func(s string) bool {
+		obj, err := readFirst(s + ")")
+		return err == nil && sx.IsNil(obj)
+	})
+	var offsets []int
+	for elem := range spec.Values() {
+		readsElem := func(s string) bool {
+			obj, err := readFirst(s)
+			return err == nil && obj.IsEqual(elem)
+		}
+		end := prev + shortestRead(text[prev:], readsElem)
+		start := end
+		for i := end - 1; i >= prev; i-- {
+			if utf8.RuneStart(text[i]) && readsElem(text[i:end]) {
+				start = i
+				break
+			}
+		}
+		offsets = append(offsets, start)
+		prev = end
+	}
+	return offsets
+}
+
+// shortestRead returns the length of the shortest non-empty prefix of text,
+// which satisfies reads. If there is no such prefix, the length of text is
+// returned.
+func shortestRead(text string, reads func(string) bool) int {
+	for i := 1; i < len(text); i++ {
+		if utf8.RuneStart(text[i]) && reads(text[:i]) {
+			return i
+		}
+	}
+	return len(text)
+}
+
+func readFirst(text string) (sx.Object, error) {
+	return sxreader.MakeReader(strings.NewReader(text)).Read()
+}
+
+// lineColumn returns the line and column of the byte offset, both starting
+// with 1.
+func lineColumn(text string, offset int) (int, int) {
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return line, col
+}
+
+// Handle registers the Sx procedure (lambda (w r) ...) for the pattern on the
+// given mux. An invalid or conflicting pattern results in an error.
+func (rt *Routes) Handle(mux *http.ServeMux, pattern string, proc sxeval.Callable) error {
+	if err := HandleMux(mux, pattern, &routeHandler{ev: &rt.ev, proc: proc}); err != nil {
+		return err
+	}
+	rt.mx.Lock()
+	rt.patterns[mux] = append(rt.patterns[mux], pattern)
+	rt.mx.Unlock()
+	return nil
+}
+
+// registered returns the patterns that were registered on the given mux.
+func (rt *Routes) registered(mux *http.ServeMux) []string {
+	rt.mx.Lock()
+	defer rt.mx.Unlock()
+	return slices.Clone(rt.patterns[mux])
+}
+
+func (rt *Routes) parseRoute(route sx.Object) (string, sxeval.Callable, error) {
+	lst, isPair := sx.GetPair(route)
+	if !isPair || lst.Length() != 3 {
+		return "", nil, fmt.Errorf("route must be a list (METHOD \"pattern\" handler)")
+	}
+	method, isSymbol := sx.GetSymbol(lst.Car())
+	if !isSymbol {
+		return "", nil, fmt.Errorf("method must be a symbol, but got %T/%v", lst.Car(), lst.Car())
+	}
+	lst = lst.Tail()
+	path, isString := sx.GetString(lst.Car())
+	if !isString {
+		return "", nil, fmt.Errorf("pattern must be a string, but got %T/%v", lst.Car(), lst.Car())
+	}
+	lst = lst.Tail()
+	sym, isSymbol := sx.GetSymbol(lst.Car())
+	if !isSymbol {
+		return "", nil, fmt.Errorf("handler must be a symbol, but got %T/%v", lst.Car(), lst.Car())
+	}
+	proc, found := rt.resolve(sym)
+	if !found {
+		return "", nil, fmt.Errorf("no procedure found for handler %v", sym)
+	}
+
+	pattern := path.GetValue()
+	if m := method.GetValue(); m != "ANY" {
+		pattern = m + " " + pattern
+	}
+	return pattern, proc, nil
+}
+
+// HandleMux registers the handler on the mux, but returns an error instead of
+// panicking if the pattern is invalid or conflicts with an already registered
+// one.
+func HandleMux(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
+
+type routeHandler struct {
+	ev   *evaluator
+	proc sxeval.Callable
+}
+
+func (rh *routeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rh.ev.serve(w, r, rh.proc, nil)
+}
+
+// PathValue is a builtin that returns the value of a wildcard of the matched
+// route pattern, or nil if there is no such wildcard.
+var PathValue = sxeval.Builtin{
+	Name:     "request-path-value",
+	MinArity: 2,
+	MaxArity: 2,
+	TestPure: sxeval.AssertPure,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		r, err := GetBuiltinRequest(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		name, err := sxbuiltins.GetString(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		if val := r.GetValue().PathValue(name.GetValue()); val != "" {
+			return sx.MakeString(val), nil
+		}
+		return sx.Nil(), nil
+	},
+}
@@ -0,0 +1,169 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestRoutesRegister(t *testing.T) {
+	t.Parallel()
+	resolve := func(sym *sx.Symbol) (sxeval.Callable, bool) {
+		if sym.GetValue() == "missing" {
+			return nil, false
+		}
+		return &sxhttp.Method, true
+	}
+	testcases := []struct {
+		name string
+		src  string
+		pos  int
+	}{
+		{"empty", `()`, 0},
+		{"simple", `((GET "/users/{id}" user-page) (POST "/users" create-user))`, 0},
+		{"any", `((ANY "/" home))`, 0},
+		{"conflict", `((GET "/a/{id}" a) (GET "/a/{name}" b))`, 2},
+		{"no-handler", `((GET "/a" a) (GET "/b" missing))`, 2},
+		{"no-list", `(GET)`, 1},
+		{"short", `((GET "/"))`, 1},
+		{"method-string", `(("GET" "/" home))`, 1},
+		{"pattern-symbol", `((GET home home))`, 1},
+		{"invalid-pattern", `((GET "no-slash" home))`, 1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, err := sxreader.MakeReader(strings.NewReader(tc.src)).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec, _ := sx.GetPair(val)
+			mux := http.NewServeMux()
+			err = sxhttp.NewRoutes(nil, resolve).Register(mux, spec)
+			if tc.pos == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if _, pattern := mux.Handler(httptest.NewRequest("GET", "/a", nil)); pattern != "" {
+				t.Errorf("route %q registered, although the specification is invalid", pattern)
+			}
+			var re *sxhttp.RouteError
+			if !errors.As(err, &re) {
+				t.Fatalf("expected route error at %d, but got %v", tc.pos, err)
+			}
+			if re.Pos != tc.pos {
+				t.Errorf("expected error at route %d, but got %v", tc.pos, re)
+			}
+		})
+	}
+}
+
+func TestRoutesHandle(t *testing.T) {
+	t.Parallel()
+	rt := sxhttp.NewRoutes(nil, nil)
+	mux := http.NewServeMux()
+	if err := rt.Handle(mux, "GET /a", &sxhttp.Method); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Handle(mux, "GET /a", &sxhttp.Method); err == nil {
+		t.Error("conflicting pattern must result in an error")
+	}
+	if err := rt.Handle(mux, "no-slash", &sxhttp.Method); err == nil {
+		t.Error("invalid pattern must result in an error")
+	}
+}
+
+func TestRoutesHandleAll(t *testing.T) {
+	t.Parallel()
+	rt := sxhttp.NewRoutes(nil, nil)
+	mux := http.NewServeMux()
+	if err := rt.HandleAll(mux, []sxhttp.Route{{Pattern: "GET /a", Proc: &sxhttp.Method}, {Pattern: "GET /b", Proc: &sxhttp.Method}}); err != nil {
+		t.Fatal(err)
+	}
+	err := rt.HandleAll(mux, []sxhttp.Route{{Pattern: "GET /c", Proc: &sxhttp.Method}, {Pattern: "GET /a", Proc: &sxhttp.Method}})
+	var re *sxhttp.RouteError
+	if !errors.As(err, &re) || re.Pos != 2 {
+		t.Fatalf("expected conflict at route 2, but got %v", err)
+	}
+	if _, pattern := mux.Handler(httptest.NewRequest("GET", "/c", nil)); pattern != "" {
+		t.Errorf("route %q registered, although a later route conflicts", pattern)
+	}
+	if err = rt.HandleAll(mux, []sxhttp.Route{{Pattern: "no-slash", Proc: &sxhttp.Method}}); err == nil {
+		t.Error("invalid pattern must result in an error")
+	}
+}
+
+func TestRoutesRegisterSource(t *testing.T) {
+	t.Parallel()
+	resolve := func(sym *sx.Symbol) (sxeval.Callable, bool) {
+		return &sxhttp.Method, sym.GetValue() != "missing"
+	}
+	testcases := []struct {
+		name   string
+		src    string
+		source string
+	}{
+		{"ok", "((GET \"/a\" a))", ""},
+		{"first", "((GET \"/a\" missing))", "routes.sxn:1:2"},
+		{"lines", "; routes\n(\n  (GET \"/a\" a) ; home\n  (GET \"/b\" missing))", "routes.sxn:4:3"},
+		{"conflict", "((GET \"/a/{id}\" a)\n\t(GET \"/a/{name}\" a))", "routes.sxn:2:2"},
+		{"escapes", "((GET \"/a)\\\"\" a) ; (\n (GET \"/b\" missing))", "routes.sxn:2:2"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := sxhttp.NewRoutes(nil, resolve).RegisterSource(http.NewServeMux(), "routes.sxn", strings.NewReader(tc.src))
+			if tc.source == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var re *sxhttp.RouteError
+			if !errors.As(err, &re) {
+				t.Fatalf("expected route error at %s, but got %v", tc.source, err)
+			}
+			if re.Source != tc.source {
+				t.Errorf("expected error at %s, but got %v", tc.source, re)
+			}
+		})
+	}
+}
+
+func TestRoutesRegisterConflict(t *testing.T) {
+	t.Parallel()
+	resolve := func(*sx.Symbol) (sxeval.Callable, bool) { return &sxhttp.Method, true }
+	rt := sxhttp.NewRoutes(nil, resolve)
+	mux := http.NewServeMux()
+	if err := rt.RegisterSource(mux, "a", strings.NewReader(`((GET "/a" a))`)); err != nil {
+		t.Fatal(err)
+	}
+	err := rt.RegisterSource(mux, "b", strings.NewReader(`((GET "/b" b) (GET "/a" a))`))
+	var re *sxhttp.RouteError
+	if !errors.As(err, &re) || re.Pos != 2 {
+		t.Fatalf("expected conflict at route 2, but got %v", err)
+	}
+	if _, pattern := mux.Handler(httptest.NewRequest("GET", "/b", nil)); pattern != "" {
+		t.Errorf("route %q registered, although a later route conflicts", pattern)
+	}
+}
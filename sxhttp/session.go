@@ -0,0 +1,596 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+)
+
+// SessionStore stores session data on the server side. The data is already
+// serialized.
+type SessionStore interface {
+	// Load returns the data of the given session. If there is no such session,
+	// nil data must be returned.
+	Load(ctx context.Context, id string) ([]byte, error)
+
+	// Save stores the data of the given session, which expires at the given
+	// time. A zero time means that the session does not expire.
+	Save(ctx context.Context, id string, data []byte, expires time.Time) error
+
+	// Delete removes the given session.
+	Delete(ctx context.Context, id string) error
+}
+
+// Sessions manages login sessions, that are identified by a cookie.
+//
+// Session data are Sx values that can be printed and read back, e.g. strings,
+// numbers, symbols, and lists of them. Without a SessionStore, all data is
+// stored in the cookie itself. The cookie is always signed with HMAC-SHA256,
+// and optionally encrypted with AES-GCM.
+//
+// Multiple keys are supported to allow key rotation: the first key is used to
+// sign and encrypt new cookies, all keys are accepted for existing cookies.
+type Sessions struct {
+	name    string
+	keys    []sessionKey
+	encrypt bool
+	store   SessionStore
+	maxAge  time.Duration
+}
+
+type sessionKey struct {
+	sign []byte
+	aead cipher.AEAD
+}
+
+// Errors returned by session management.
+var (
+	ErrSessionInvalid  = errors.New("invalid session cookie")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrSessionTooLarge = errors.New("session data too large for cookie")
+)
+
+// maxCookieSize is the maximum size of a cookie value, that is accepted by
+// all major browsers.
+const maxCookieSize = 4000
+
+// NewSessions creates a session manager, that uses a cookie with the given
+// name. At least one key must be given, the first one is used to sign new
+// cookies.
+func NewSessions(name string, keys ...[]byte) (*Sessions, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no session key given")
+	}
+	s := &Sessions{name: name, keys: make([]sessionKey, 0, len(keys))}
+	for i, key := range keys {
+		if len(key) < 16 {
+			return nil, fmt.Errorf("session key %d is too short", i+1)
+		}
+		block, err := aes.NewCipher(deriveKey(key, "encrypt"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, sessionKey{sign: deriveKey(key, "sign"), aead: aead})
+	}
+	return s, nil
+}
+
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	io.WriteString(mac, "sxhttp session "+purpose)
+	return mac.Sum(nil)
+}
+
+// SetEncryption will encrypt session cookies, in addition to signing them.
+func (s *Sessions) SetEncryption() *Sessions { s.encrypt = true; return s }
+
+// SetStore stores session data on the server side. The cookie then contains
+// only a signed session identifier.
+func (s *Sessions) SetStore(store SessionStore) *Sessions { s.store = store; return s }
+
+// SetMaxAge sets the maximum age of a session. A zero value results in a
+// browser session.
+func (s *Sessions) SetMaxAge(maxAge time.Duration) *Sessions { s.maxAge = maxAge; return s }
+
+// ----- Session state -------------------------------------------------------
+
+type sessionState struct {
+	id   string // only used with a SessionStore
+	data map[string]sx.Object
+}
+
+type sessionCtxKey struct{ name string }
+
+type sessionHolder struct {
+	mx     sync.Mutex
+	loaded bool
+	state  sessionState
+}
+
+// Wrap returns a handler that caches the session for the duration of the
+// request. Without it, session values that were changed within a request are
+// not visible to Get within that request.
+func (s *Sessions) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), sessionCtxKey{s.name}, &sessionHolder{})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withState calls the function with the current state of the session. The
+// response writer may be nil, if the state is only read.
+func (s *Sessions) withState(w http.ResponseWriter, r *http.Request, fn func(*sessionState) error) error {
+	holder, hasHolder := r.Context().Value(sessionCtxKey{s.name}).(*sessionHolder)
+	if !hasHolder {
+		state := s.load(w, r)
+		return fn(&state)
+	}
+	holder.mx.Lock()
+	defer holder.mx.Unlock()
+	if !holder.loaded {
+		holder.state = s.load(w, r)
+		holder.loaded = true
+	}
+	return fn(&holder.state)
+}
+
+// load retrieves the session of the request. If the session cookie was
+// already set on the response writer, this cookie is used, so that changes
+// within the request are not lost, even without Wrap. Invalid or expired
+// sessions result in an empty session.
+func (s *Sessions) load(w http.ResponseWriter, r *http.Request) sessionState {
+	state := sessionState{data: map[string]sx.Object{}}
+	cval, found := s.pendingCookie(w)
+	if !found {
+		cookie, err := r.Cookie(s.name)
+		if err != nil {
+			return state
+		}
+		cval = cookie.Value
+	}
+	value, err := s.decodeCookie(cval)
+	if err != nil {
+		return state
+	}
+	if s.store != nil {
+		id := string(value)
+		if value, err = s.store.Load(r.Context(), id); err != nil || value == nil {
+			return state
+		}
+		state.id = id
+	}
+	if data, errData := decodeSessionData(value); errData == nil {
+		state.data = data
+	}
+	return state
+}
+
+// pendingCookie returns the value of the session cookie, that was set on the
+// response writer.
+func (s *Sessions) pendingCookie(w http.ResponseWriter) (string, bool) {
+	if w == nil {
+		return "", false
+	}
+	for _, line := range w.Header()["Set-Cookie"] {
+		if cookie, err := http.ParseSetCookie(line); err == nil && cookie.Name == s.name {
+			return cookie.Value, true
+		}
+	}
+	return "", false
+}
+
+// save writes the session to the store and sets the cookie.
+func (s *Sessions) save(w http.ResponseWriter, r *http.Request, state *sessionState) error {
+	value := encodeSessionData(state.data)
+	if s.store != nil {
+		if state.id == "" {
+			state.id = rand.Text()
+		}
+		var expires time.Time
+		if s.maxAge > 0 {
+			expires = time.Now().Add(s.maxAge)
+		}
+		if err := s.store.Save(r.Context(), state.id, value, expires); err != nil {
+			return err
+		}
+		value = []byte(state.id)
+	}
+	cval, err := s.encodeCookie(value, time.Now())
+	if err != nil {
+		return err
+	}
+	cookie := s.makeCookie(r, cval)
+	if s.maxAge > 0 {
+		cookie.MaxAge = int(s.maxAge / time.Second)
+	}
+	setCookie(w, cookie)
+	return nil
+}
+
+func (s *Sessions) makeCookie(r *http.Request, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.name,
+		Value:    value,
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// setCookie sets the cookie, replacing a cookie with the same name that was
+// set before within this response.
+func setCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	h := w.Header()
+	prefix := cookie.Name + "="
+	h["Set-Cookie"] = slices.DeleteFunc(h["Set-Cookie"], func(s string) bool {
+		return strings.HasPrefix(s, prefix)
+	})
+	http.SetCookie(w, cookie)
+}
+
+// Get returns the session value for the given key, or nil.
+func (s *Sessions) Get(r *http.Request, key string) sx.Object {
+	var result sx.Object = sx.Nil()
+	_ = s.withState(nil, r, func(state *sessionState) error {
+		if obj, found := state.data[key]; found {
+			result = obj
+		}
+		return nil
+	})
+	return result
+}
+
+// Set stores a session value under the given key. A nil value removes the
+// key. Since a cookie is set, it must be called before the response body is
+// written.
+func (s *Sessions) Set(w http.ResponseWriter, r *http.Request, key string, val sx.Object) error {
+	if !sx.IsNil(val) {
+		if err := checkSessionValue(val); err != nil {
+			return err
+		}
+	}
+	return s.withState(w, r, func(state *sessionState) error {
+		if sx.IsNil(val) {
+			delete(state.data, key)
+		} else {
+			state.data[key] = val
+		}
+		return s.save(w, r, state)
+	})
+}
+
+// Renew issues a new session for the current session data and invalidates
+// the old one. It should be called whenever the privileges of a user change,
+// e.g. after a login, to prevent session fixation. Since a cookie is set, it
+// must be called before the response body is written.
+func (s *Sessions) Renew(w http.ResponseWriter, r *http.Request) error {
+	return s.withState(w, r, func(state *sessionState) error {
+		if s.store != nil && state.id != "" {
+			if err := s.store.Delete(r.Context(), state.id); err != nil {
+				return err
+			}
+			state.id = ""
+		}
+		return s.save(w, r, state)
+	})
+}
+
+// Clear removes all session data and deletes the cookie.
+func (s *Sessions) Clear(w http.ResponseWriter, r *http.Request) error {
+	return s.withState(w, r, func(state *sessionState) error {
+		var err error
+		if s.store != nil && state.id != "" {
+			err = s.store.Delete(r.Context(), state.id)
+		}
+		*state = sessionState{data: map[string]sx.Object{}}
+		cookie := s.makeCookie(r, "")
+		cookie.MaxAge = -1
+		setCookie(w, cookie)
+		return err
+	})
+}
+
+// ----- Serialisation -------------------------------------------------------
+
+// checkSessionValue returns an error, if the value cannot be read back after
+// it was printed.
+func checkSessionValue(val sx.Object) error {
+	s := val.String()
+	obj, err := sxreader.MakeReader(strings.NewReader(s)).Read()
+	if err != nil || !val.IsEqual(obj) {
+		return fmt.Errorf("session value cannot be serialised: %v", s)
+	}
+	return nil
+}
+
+// encodeSessionData prints the session data as an association list, sorted
+// by key.
+func encodeSessionData(data map[string]sx.Object) []byte {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var lb sx.ListBuilder
+	for _, key := range keys {
+		lb.Add(sx.Cons(sx.MakeString(key), data[key]))
+	}
+	return []byte(lb.List().String())
+}
+
+func decodeSessionData(value []byte) (map[string]sx.Object, error) {
+	obj, err := sxreader.MakeReader(strings.NewReader(string(value))).Read()
+	if err != nil {
+		return nil, err
+	}
+	lst, isPair := sx.GetPair(obj)
+	if !isPair {
+		return nil, ErrSessionInvalid
+	}
+	data := make(map[string]sx.Object, lst.Length())
+	for elem := range lst.Values() {
+		pair, isPair := sx.GetPair(elem)
+		if !isPair || pair == nil {
+			return nil, ErrSessionInvalid
+		}
+		key, isString := sx.GetString(pair.Car())
+		if !isString {
+			return nil, ErrSessionInvalid
+		}
+		data[key.GetValue()] = pair.Cdr()
+	}
+	return data, nil
+}
+
+// encodeCookie creates the cookie value: the payload consists of the issue
+// time and the value, optionally encrypted. It is followed by a signature.
+func (s *Sessions) encodeCookie(value []byte, now time.Time) (string, error) {
+	key := s.keys[0]
+	payload := binary.BigEndian.AppendUint64(nil, uint64(now.Unix()))
+	payload = append(payload, value...)
+	if s.encrypt {
+		nonce := make([]byte, key.aead.NonceSize())
+		rand.Read(nonce)
+		payload = key.aead.Seal(nonce, nonce, payload, []byte(s.name))
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	result := encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(key, encoded))
+	if len(result) > maxCookieSize {
+		return "", ErrSessionTooLarge
+	}
+	return result, nil
+}
+
+func (s *Sessions) sign(key sessionKey, encoded string) []byte {
+	mac := hmac.New(sha256.New, key.sign)
+	io.WriteString(mac, s.name)
+	io.WriteString(mac, "|")
+	io.WriteString(mac, encoded)
+	return mac.Sum(nil)
+}
+
+// decodeCookie verifies the cookie value and returns the stored value.
+func (s *Sessions) decodeCookie(cval string) ([]byte, error) {
+	encoded, sig, found := strings.Cut(cval, ".")
+	if !found {
+		return nil, ErrSessionInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, ErrSessionInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrSessionInvalid
+	}
+	for _, key := range s.keys {
+		if !hmac.Equal(mac, s.sign(key, encoded)) {
+			continue
+		}
+		if s.encrypt {
+			nonceSize := key.aead.NonceSize()
+			if len(payload) < nonceSize {
+				return nil, ErrSessionInvalid
+			}
+			payload, err = key.aead.Open(nil, payload[:nonceSize], payload[nonceSize:], []byte(s.name))
+			if err != nil {
+				return nil, ErrSessionInvalid
+			}
+		}
+		if len(payload) < 8 {
+			return nil, ErrSessionInvalid
+		}
+		issued := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+		if s.maxAge > 0 && time.Since(issued) > s.maxAge {
+			return nil, ErrSessionExpired
+		}
+		return payload[8:], nil
+	}
+	return nil, ErrSessionInvalid
+}
+
+// ----- MemoryStore ---------------------------------------------------------
+
+// MemoryStore is a SessionStore that holds all sessions in main memory.
+type MemoryStore struct {
+	mx       sync.Mutex
+	sessions map[string]memorySession
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore creates a new, empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]memorySession{}}
+}
+
+// Load returns the data of the given session.
+func (ms *MemoryStore) Load(_ context.Context, id string) ([]byte, error) {
+	ms.mx.Lock()
+	defer ms.mx.Unlock()
+	sess, found := ms.sessions[id]
+	if !found {
+		return nil, nil
+	}
+	if !sess.expires.IsZero() && time.Now().After(sess.expires) {
+		delete(ms.sessions, id)
+		return nil, nil
+	}
+	return sess.data, nil
+}
+
+// Save stores the data of the given session.
+func (ms *MemoryStore) Save(_ context.Context, id string, data []byte, expires time.Time) error {
+	ms.mx.Lock()
+	ms.sessions[id] = memorySession{data: slices.Clone(data), expires: expires}
+	ms.mx.Unlock()
+	return nil
+}
+
+// Delete removes the given session.
+func (ms *MemoryStore) Delete(_ context.Context, id string) error {
+	ms.mx.Lock()
+	delete(ms.sessions, id)
+	ms.mx.Unlock()
+	return nil
+}
+
+// Cleanup removes all expired sessions.
+func (ms *MemoryStore) Cleanup() {
+	now := time.Now()
+	ms.mx.Lock()
+	for id, sess := range ms.sessions {
+		if !sess.expires.IsZero() && now.After(sess.expires) {
+			delete(ms.sessions, id)
+		}
+	}
+	ms.mx.Unlock()
+}
+
+// ----- Builtins ------------------------------------------------------------
+
+// MakeSessionGetBuiltin returns a builtin that provides the
+// (session-get r key) function. It returns nil, if there is no value.
+func MakeSessionGetBuiltin(s *Sessions) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "session-get",
+		MinArity: 2,
+		MaxArity: 2,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			r, err := GetBuiltinRequest(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			key, err := getBuiltinKey(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			return s.Get(r.GetValue(), key), nil
+		},
+	}
+}
+
+// MakeSessionSetBuiltin returns a builtin that provides the
+// (session-set! w r key val) function.
+func MakeSessionSetBuiltin(s *Sessions) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "session-set!",
+		MinArity: 4,
+		MaxArity: 4,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			r, err := GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			key, err := getBuiltinKey(args[2], 2)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			return sx.Nil(), s.Set(w.GetValue(), r.GetValue(), key, args[3])
+		},
+	}
+}
+
+// MakeSessionRenewBuiltin returns a builtin that provides the
+// (session-renew! w r) function.
+func MakeSessionRenewBuiltin(s *Sessions) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "session-renew!",
+		MinArity: 2,
+		MaxArity: 2,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			r, err := GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			return sx.Nil(), s.Renew(w.GetValue(), r.GetValue())
+		},
+	}
+}
+
+// MakeSessionClearBuiltin returns a builtin that provides the
+// (session-clear! w r) function.
+func MakeSessionClearBuiltin(s *Sessions) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "session-clear!",
+		MinArity: 2,
+		MaxArity: 2,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			r, err := GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			return sx.Nil(), s.Clear(w.GetValue(), r.GetValue())
+		},
+	}
+}
@@ -0,0 +1,190 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+var (
+	oldKey = []byte("0123456789abcdef-old")
+	newKey = []byte("0123456789abcdef-new")
+)
+
+// sessionRoundtrip sets a value with the first session manager and reads it
+// with the second one.
+func sessionRoundtrip(t *testing.T, set, get *sxhttp.Sessions, val sx.Object) sx.Object {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := set.Set(w, httptest.NewRequest("GET", "/", nil), "user", val); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected one cookie, but got %v", cookies)
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	return get.Get(r, "user")
+}
+
+func newSessions(t *testing.T, keys ...[]byte) *sxhttp.Sessions {
+	t.Helper()
+	s, err := sxhttp.NewSessions("sess", keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSessionCookie(t *testing.T) {
+	t.Parallel()
+	val := sx.MakeList(sx.MakeString("detlef"), sx.Int64(17), sx.MakeSymbol("admin"))
+	testcases := []struct {
+		name     string
+		set, get *sxhttp.Sessions
+		valid    bool
+	}{
+		{"signed", newSessions(t, newKey), newSessions(t, newKey), true},
+		{"encrypted", newSessions(t, newKey).SetEncryption(), newSessions(t, newKey).SetEncryption(), true},
+		{"rotated", newSessions(t, oldKey), newSessions(t, newKey, oldKey), true},
+		{"rotated-encrypted", newSessions(t, oldKey).SetEncryption(), newSessions(t, newKey, oldKey).SetEncryption(), true},
+		{"retired", newSessions(t, oldKey), newSessions(t, newKey), false},
+		{"store", newSessions(t, newKey).SetStore(sxhttp.NewMemoryStore()), newSessions(t, newKey), false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sessionRoundtrip(t, tc.set, tc.get, val)
+			if tc.valid && !val.IsEqual(got) {
+				t.Errorf("expected %v, but got %v", val, got)
+			} else if !tc.valid && !sx.IsNil(got) {
+				t.Errorf("expected nil, but got %v", got)
+			}
+		})
+	}
+}
+
+func TestSessionStore(t *testing.T) {
+	t.Parallel()
+	s := newSessions(t, newKey).SetStore(sxhttp.NewMemoryStore())
+	val := sx.MakeString("detlef")
+	if got := sessionRoundtrip(t, s, s, val); !val.IsEqual(got) {
+		t.Errorf("expected %v, but got %v", val, got)
+	}
+}
+
+func TestSessionWithinRequest(t *testing.T) {
+	t.Parallel()
+	s := newSessions(t, newKey)
+	var got sx.Object
+	h := s.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Set(w, r, "a", sx.Int64(1)); err != nil {
+			t.Error(err)
+		}
+		if err := s.Set(w, r, "b", sx.Int64(2)); err != nil {
+			t.Error(err)
+		}
+		got = s.Get(r, "a")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if !sx.Int64(1).IsEqual(got) {
+		t.Errorf("expected 1, but got %v", got)
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 1 {
+		t.Errorf("expected one cookie, but got %v", cookies)
+	}
+}
+
+func TestSessionWithoutWrap(t *testing.T) {
+	t.Parallel()
+	s := newSessions(t, newKey)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := s.Set(w, r, "a", sx.Int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(w, r, "b", sx.Int64(2)); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected one cookie, but got %v", cookies)
+	}
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	if got := s.Get(r, "a"); !sx.Int64(1).IsEqual(got) {
+		t.Errorf("first value lost, got %v", got)
+	}
+	if got := s.Get(r, "b"); !sx.Int64(2).IsEqual(got) {
+		t.Errorf("expected 2, but got %v", got)
+	}
+}
+
+func TestSessionRenew(t *testing.T) {
+	t.Parallel()
+	s := newSessions(t, newKey).SetStore(sxhttp.NewMemoryStore())
+	w := httptest.NewRecorder()
+	if err := s.Set(w, httptest.NewRequest("GET", "/", nil), "user", sx.MakeString("detlef")); err != nil {
+		t.Fatal(err)
+	}
+	oldCookie := w.Result().Cookies()[0]
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(oldCookie)
+	w = httptest.NewRecorder()
+	if err := s.Renew(w, r); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == oldCookie.Value {
+		t.Fatalf("expected a new cookie, but got %v", cookies)
+	}
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	if got := s.Get(r, "user"); !sx.MakeString("detlef").IsEqual(got) {
+		t.Errorf("session data lost, got %v", got)
+	}
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(oldCookie)
+	if got := s.Get(r, "user"); !sx.IsNil(got) {
+		t.Errorf("old session must be invalid, but got %v", got)
+	}
+}
+
+func TestSessionClear(t *testing.T) {
+	t.Parallel()
+	s := newSessions(t, newKey)
+	w := httptest.NewRecorder()
+	if err := s.Clear(w, httptest.NewRequest("GET", "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("expected a deleted cookie, but got %v", cookies)
+	}
+}
+
+func TestSessionInvalidValue(t *testing.T) {
+	t.Parallel()
+	s := newSessions(t, newKey)
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := s.Set(httptest.NewRecorder(), r, "r", sxhttp.MakeRequest(r)); err == nil {
+		t.Error("a request object must not be stored in a session")
+	}
+}
@@ -0,0 +1,268 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+)
+
+// ----- SxEventStream -------------------------------------------------------
+
+// SxEventStream is a stream of server-sent events, seen as a Sx object.
+//
+// The stream is closed, when the context of the request is done.
+type SxEventStream struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	ctx context.Context
+	gen *sxhtml.Generator
+}
+
+// Event is a server-sent event. Empty fields are not sent.
+type Event struct {
+	Event string
+	ID    string
+	Retry int // reconnection time in milliseconds
+	Data  string
+}
+
+// ErrEventStreamClosed is returned, if an event is sent to a closed stream.
+var ErrEventStreamClosed = errors.New("event stream closed")
+
+// NewEventStream starts a stream of server-sent events as the response of the
+// given request. The generator is used to render data that is not a string.
+func NewEventStream(w http.ResponseWriter, r *http.Request, gen *sxhtml.Generator) (*SxEventStream, error) {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return nil, err
+	}
+	return &SxEventStream{w: w, rc: rc, ctx: r.Context(), gen: gen}, nil
+}
+
+// IsNil returns true, if the object is a nil value.
+func (es *SxEventStream) IsNil() bool { return es == nil }
+
+// IsAtom returns true if this object is an atomic object.
+func (*SxEventStream) IsAtom() bool { return true }
+
+// IsTrue returns true if the event stream can be interpreted as a "true" value.
+func (es *SxEventStream) IsTrue() bool { return es != nil }
+
+// IsEqual returns true, if this event stream is identical to the given object.
+func (es *SxEventStream) IsEqual(other sx.Object) bool {
+	if es == nil {
+		return sx.IsNil(other)
+	}
+	otherES, isES := GetEventStream(other)
+	return isES && es == otherES
+}
+func (es *SxEventStream) String() string {
+	return fmt.Sprintf("#<SxEventStream:%p>", es)
+}
+
+// GoString returns the Go representation.
+func (es *SxEventStream) GoString() string { return es.String() }
+
+// IsClosed returns true, if no more events can be sent.
+func (es *SxEventStream) IsClosed() bool { return es.ctx.Err() != nil }
+
+// Send the event to the client.
+func (es *SxEventStream) Send(ev Event) error {
+	if es.IsClosed() {
+		return ErrEventStreamClosed
+	}
+	var sb strings.Builder
+	writeEventField(&sb, "event", ev.Event)
+	writeEventField(&sb, "id", ev.ID)
+	if ev.Retry > 0 {
+		writeEventField(&sb, "retry", strconv.Itoa(ev.Retry))
+	}
+	for line := range strings.Lines(ev.Data) {
+		writeEventField(&sb, "data", strings.TrimRight(line, "\r\n"))
+	}
+	if ev.Data == "" {
+		sb.WriteString("data:\n")
+	}
+	sb.WriteByte('\n')
+	if _, err := es.w.Write([]byte(sb.String())); err != nil {
+		return err
+	}
+	return es.rc.Flush()
+}
+
+// writeEventField writes a field. Since a newline would end the field, it is
+// replaced by a space.
+func writeEventField(sb *strings.Builder, name, value string) {
+	if value == "" && name != "data" {
+		return
+	}
+	sb.WriteString(name)
+	sb.WriteString(": ")
+	sb.WriteString(strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+	sb.WriteByte('\n')
+}
+
+// GetEventStream returns the given sx.Object as a SxEventStream, if possible.
+func GetEventStream(obj sx.Object) (*SxEventStream, bool) {
+	if sx.IsNil(obj) {
+		return nil, false
+	}
+	es, ok := obj.(*SxEventStream)
+	return es, ok
+}
+
+// GetBuiltinEventStream returns the given sx.Object as a SxEventStream. If this
+// is not possible, an error is returned.
+//
+// This function can be used as a helper function to implement sxeval.Builtin.
+func GetBuiltinEventStream(arg sx.Object, pos int) (*SxEventStream, error) {
+	if es, isES := GetEventStream(arg); isES {
+		return es, nil
+	}
+	return nil, fmt.Errorf("argument %d is not an event stream, but %T/%v", pos+1, arg, arg)
+}
+
+// ----- Builtins ------------------------------------------------------------
+
+// MakeEventStreamBuiltin returns a builtin that provides the
+// (sse-start w r) function. It starts a stream of server-sent events and
+// returns the stream object. A generator is created for every stream to
+// render SxHTML data.
+func MakeEventStreamBuiltin(makeGen func(*http.Request) *sxhtml.Generator) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "sse-start",
+		MinArity: 2,
+		MaxArity: 2,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			r, err := GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return sx.Nil(), err
+			}
+			req := r.GetValue()
+			es, err := NewEventStream(w.GetValue(), req, makeGen(req))
+			if err != nil {
+				return sx.Nil(), err
+			}
+			return es, nil
+		},
+	}
+}
+
+// Symbols for the options of "sse-send".
+var (
+	SymEvent = sx.MakeSymbol("event")
+	SymID    = sx.MakeSymbol("id")
+	SymRetry = sx.MakeSymbol("retry")
+)
+
+// EventSend is a builtin that sends an event: (sse-send stream data opts).
+// Data is a string, or a SxHTML tree that will be rendered as HTML. The
+// optional association list opts may contain values for the keys "event",
+// "id", and "retry". If the stream is closed, an error is returned.
+var EventSend = sxeval.Builtin{
+	Name:     "sse-send",
+	MinArity: 2,
+	MaxArity: 3,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		es, err := GetBuiltinEventStream(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		var ev Event
+		if s, isString := sx.GetString(args[1]); isString {
+			ev.Data = s.GetValue()
+		} else {
+			var sb strings.Builder
+			if err = es.gen.WriteHTML(&sb, args[1]); err != nil {
+				return sx.Nil(), err
+			}
+			ev.Data = sb.String()
+		}
+		if len(args) > 2 {
+			if err = parseEventOptions(&ev, args[2]); err != nil {
+				return sx.Nil(), err
+			}
+		}
+		return sx.Nil(), es.Send(ev)
+	},
+}
+
+func parseEventOptions(ev *Event, arg sx.Object) error {
+	opts, isPair := sx.GetPair(arg)
+	if !isPair {
+		return fmt.Errorf("argument 3 is not an association list, but %T/%v", arg, arg)
+	}
+	for elem := range opts.Values() {
+		pair, isPair := sx.GetPair(elem)
+		if !isPair || pair == nil {
+			continue
+		}
+		key, val := pair.Car(), pair.Cdr()
+		switch {
+		case SymEvent.IsEqual(key):
+			ev.Event = eventOptionString(val)
+		case SymID.IsEqual(key):
+			ev.ID = eventOptionString(val)
+		case SymRetry.IsEqual(key):
+			if i, isInt := val.(sx.Int64); isInt && i > 0 {
+				ev.Retry = int(i)
+			}
+		}
+	}
+	return nil
+}
+
+func eventOptionString(val sx.Object) string {
+	switch v := val.(type) {
+	case sx.String:
+		return v.GetValue()
+	case *sx.Symbol:
+		return v.GetValue()
+	case sx.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// EventClosed is a builtin that returns true, if the event stream is closed,
+// e.g. because the client disconnected: (sse-closed? stream).
+var EventClosed = sxeval.Builtin{
+	Name:     "sse-closed?",
+	MinArity: 1,
+	MaxArity: 1,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		es, err := GetBuiltinEventStream(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		return sx.MakeBoolean(es.IsClosed()), nil
+	},
+}
@@ -0,0 +1,64 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestEventStream(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	es, err := sxhttp.NewEventStream(w, r, sxhtml.NewGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("wrong content type: %q", got)
+	}
+
+	if err = es.Send(sxhttp.Event{Data: "line1\nline2"}); err != nil {
+		t.Fatal(err)
+	}
+	html := sx.MakeList(sxhtml.MakeSymbol("p"), sx.MakeString("a<b"))
+	opts := sx.MakeList(
+		sx.Cons(sxhttp.SymEvent, sx.MakeString("update")),
+		sx.Cons(sxhttp.SymID, sx.Int64(7)),
+		sx.Cons(sxhttp.SymRetry, sx.Int64(1000)),
+	)
+	if _, err = sxhttp.EventSend.Fn(nil, sx.Vector{es, html, opts}, nil); err != nil {
+		t.Fatal(err)
+	}
+	exp := "data: line1\ndata: line2\n\nevent: update\nid: 7\nretry: 1000\ndata: <p>a&lt;b</p>\n\n"
+	if got := w.Body.String(); got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+
+	cancel()
+	if !es.IsClosed() {
+		t.Error("stream must be closed after context is cancelled")
+	}
+	if err = es.Send(sxhttp.Event{Data: "late"}); !errors.Is(err, sxhttp.ErrEventStreamClosed) {
+		t.Errorf("expected closed stream error, but got %v", err)
+	}
+}
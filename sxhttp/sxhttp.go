@@ -17,19 +17,28 @@ package sxhttp
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
 	"t73f.de/r/sx/sxeval"
 )
 
 // ----- SxContext -----------------------------------------------------------
 
 // SxContext is a context.Context, seen as a Sx object.
-type SxContext struct{ val context.Context }
+//
+// A SxContext is comparable, so it can be compared with "==" and used as a
+// map key. Therefore, the cancel function of a derived context is stored
+// behind a pointer.
+type SxContext struct {
+	val    context.Context
+	cancel *context.CancelFunc
+}
 
 // MakeContext creates a SxContext from a context.Context.
-func MakeContext(ctx context.Context) SxContext { return SxContext{ctx} }
+func MakeContext(ctx context.Context) SxContext { return SxContext{val: ctx} }
 
 // GetValue returns the context.Context value.
 func (ctx SxContext) GetValue() context.Context { return ctx.val }
@@ -44,11 +53,10 @@ func (SxContext) IsAtom() bool { return true }
 func (SxContext) IsTrue() bool { return true }
 
 // IsEqual returns true if the sx content is equal to the given object.
+//
+// Two contexts are equal, if they wrap the identical context.Context.
 func (ctx SxContext) IsEqual(other sx.Object) bool {
-	if other.IsNil() {
-		return false
-	}
-	otherCtx, isCtx := other.(SxContext)
+	otherCtx, isCtx := GetContext(other)
 	return isCtx && ctx.val == otherCtx.val
 }
 func (ctx SxContext) String() string {
@@ -58,13 +66,18 @@ func (ctx SxContext) String() string {
 // GoString return the Go representation of the context.
 func (ctx SxContext) GoString() string { return ctx.String() }
 
-// GetContext returns the given sx.Object as a SxContext, if possible.
+// GetContext returns the given sx.Object as a SxContext, if possible. Both a
+// SxContext and a pointer to it are accepted.
 func GetContext(obj sx.Object) (SxContext, bool) {
-	if obj.IsNil() {
-		return SxContext{}, false
+	switch ctx := obj.(type) {
+	case SxContext:
+		return ctx, true
+	case *SxContext:
+		if ctx != nil {
+			return *ctx, true
+		}
 	}
-	ctx, ok := obj.(SxContext)
-	return ctx, ok
+	return SxContext{}, false
 }
 
 // GetBuiltinContext returns the given sx.Object as a SxContext. If this is not
@@ -99,6 +112,8 @@ func (*SxRequest) IsAtom() bool { return true }
 func (r *SxRequest) IsTrue() bool { return r != nil }
 
 // IsEqual returns true if the other object is equal to this request object.
+//
+// Two requests are equal, if they are the identical http.Request.
 func (r *SxRequest) IsEqual(other sx.Object) bool {
 	if r == nil {
 		return sx.IsNil(other)
@@ -106,7 +121,7 @@ func (r *SxRequest) IsEqual(other sx.Object) bool {
 	if sx.IsNil(other) {
 		return false
 	}
-	otherReq, isReq := other.(*SxRequest)
+	otherReq, isReq := GetRequest(other)
 	return isReq && r == otherReq
 }
 func (r *SxRequest) String() string {
@@ -166,6 +181,64 @@ var Context = sxeval.Builtin{
 	},
 }
 
+// Method is a builtin that returns the HTTP method of a request object.
+var Method = sxeval.Builtin{
+	Name:     "request-method",
+	MinArity: 1,
+	MaxArity: 1,
+	TestPure: sxeval.AssertPure,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		r, err := GetBuiltinRequest(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		return sx.MakeString(r.GetValue().Method), nil
+	},
+}
+
+// Header is a builtin that returns the value of a request header, or nil if
+// there is no such header.
+var Header = sxeval.Builtin{
+	Name:     "request-header",
+	MinArity: 2,
+	MaxArity: 2,
+	TestPure: sxeval.AssertPure,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		r, err := GetBuiltinRequest(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		key, err := sxbuiltins.GetString(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		if vals := r.GetValue().Header.Values(key.GetValue()); len(vals) > 0 {
+			return sx.MakeString(vals[0]), nil
+		}
+		return sx.Nil(), nil
+	},
+}
+
+// WithContext is a builtin that returns a shallow copy of a request object,
+// with the context changed to the given one.
+var WithContext = sxeval.Builtin{
+	Name:     "request-with-context",
+	MinArity: 2,
+	MaxArity: 2,
+	TestPure: sxeval.AssertPure,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		r, err := GetBuiltinRequest(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		ctx, err := GetBuiltinContext(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		return MakeRequest(r.GetValue().WithContext(ctx.GetValue())), nil
+	},
+}
+
 // ----- SxResponseWriter ----------------------------------------------------
 
 // SxResponseWriter is a http.ResponseWriter, seen as a Sx object.
@@ -187,11 +260,11 @@ func (SxResponseWriter) IsAtom() bool { return true }
 func (SxResponseWriter) IsTrue() bool { return true }
 
 // IsEqual returns true, if this response writer is equal to the given object.
+//
+// Two response writers are equal, if they wrap the identical
+// http.ResponseWriter.
 func (w SxResponseWriter) IsEqual(other sx.Object) bool {
-	if sx.IsNil(other) {
-		return false
-	}
-	otherResp, isResp := other.(*SxResponseWriter)
+	otherResp, isResp := GetResponseWriter(other)
 	return isResp && w.val == otherResp.val
 }
 func (w SxResponseWriter) String() string {
@@ -200,3 +273,117 @@ func (w SxResponseWriter) String() string {
 
 // GoString returns the Go representation.
 func (w SxResponseWriter) GoString() string { return w.String() }
+
+// GetResponseWriter returns the given sx.Object as a SxResponseWriter, if
+// possible. Both a SxResponseWriter and a pointer to it are accepted.
+func GetResponseWriter(obj sx.Object) (SxResponseWriter, bool) {
+	switch w := obj.(type) {
+	case SxResponseWriter:
+		return w, true
+	case *SxResponseWriter:
+		if w != nil {
+			return *w, true
+		}
+	}
+	return SxResponseWriter{}, false
+}
+
+// GetBuiltinResponseWriter returns the given sx.Object as a SxResponseWriter.
+// If this is not possible, an error is returned.
+//
+// This function can be used as a helper function to implement sxeval.Builtin.
+func GetBuiltinResponseWriter(arg sx.Object, pos int) (SxResponseWriter, error) {
+	if w, isWriter := GetResponseWriter(arg); isWriter {
+		return w, nil
+	}
+	return SxResponseWriter{}, fmt.Errorf("argument %d is not a http response writer, but %T/%v", pos+1, arg, arg)
+}
+
+// SetHeader is a builtin that sets a header of the response writer. It must
+// be called before the response body is written.
+var SetHeader = sxeval.Builtin{
+	Name:     "response-set-header",
+	MinArity: 3,
+	MaxArity: 3,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		key, err := sxbuiltins.GetString(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		val, err := sxbuiltins.GetString(args[2], 2)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		w.GetValue().Header().Set(key.GetValue(), val.GetValue())
+		return sx.Nil(), nil
+	},
+}
+
+// WriteHeader is a builtin that sends the response header with the given
+// status code.
+var WriteHeader = sxeval.Builtin{
+	Name:     "response-write-header",
+	MinArity: 2,
+	MaxArity: 2,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		code, err := getBuiltinInt(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		if code < 100 || code > 999 {
+			return sx.Nil(), fmt.Errorf("invalid status code: %d", code)
+		}
+		w.GetValue().WriteHeader(int(code))
+		return sx.Nil(), nil
+	},
+}
+
+// Write is a builtin that writes the given strings to the response writer.
+var Write = sxeval.Builtin{
+	Name:     "response-write",
+	MinArity: 1,
+	MaxArity: -1,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		_, err := GetBuiltinResponseWriter(arg, 0)
+		return sx.Nil(), err
+	},
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		for i := 1; i < len(args); i++ {
+			s, errString := sxbuiltins.GetString(args[i], i)
+			if errString != nil {
+				return sx.Nil(), errString
+			}
+			if _, errWrite := io.WriteString(w.GetValue(), s.GetValue()); errWrite != nil {
+				return sx.Nil(), errWrite
+			}
+		}
+		return sx.Nil(), nil
+	},
+}
+
+// Builtins returns all builtins of this package that do not need any
+// configuration. Builtins that are created by a MakeXXXBuiltin function are
+// not included.
+func Builtins() []*sxeval.Builtin {
+	return []*sxeval.Builtin{
+		&URLPath, &Context, &Method, &Header, &WithContext, &PathValue,
+		&SetHeader, &WriteHeader, &Write,
+		&ContextErr, &ContextDeadline, &ContextWithCancel, &ContextWithTimeout, &ContextCancel,
+		&Negotiation, &CacheControl, &ETag, &LastModified,
+		&EventSend, &EventClosed,
+		&WebSocketUpgrade, &WebSocketSend, &WebSocketReceive, &WebSocketClose, &WebSocketClosed,
+		&LogDebug, &LogInfo, &LogWarn, &LogError,
+	}
+}
@@ -0,0 +1,147 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+// objectcase describes a Sx object of this package, together with an object
+// that wraps the same Go value, and an object that wraps a different one.
+type objectcase struct {
+	name   string
+	obj    sx.Object
+	same   sx.Object
+	other  sx.Object
+	prefix string
+}
+
+func makeObjectcases() []objectcase {
+	ctx1 := context.Background()
+	ctx2, cancel := context.WithCancel(ctx1)
+	defer cancel()
+	ctx1Obj := sxhttp.MakeContext(ctx1)
+
+	r1 := httptest.NewRequest("GET", "/", nil)
+	r2 := httptest.NewRequest("GET", "/", nil)
+
+	w1, w2 := httptest.NewRecorder(), httptest.NewRecorder()
+	w1Obj := sxhttp.MakeResponseWriter(w1)
+
+	es1 := mustEventStream(r1)
+	es2 := mustEventStream(r2)
+
+	return []objectcase{
+		{"context", ctx1Obj, sxhttp.MakeContext(ctx1), sxhttp.MakeContext(ctx2), "#<SxContext:"},
+		{"context-ptr", &ctx1Obj, sxhttp.MakeContext(ctx1), sxhttp.MakeContext(ctx2), "#<SxContext:"},
+		{"request", sxhttp.MakeRequest(r1), sxhttp.MakeRequest(r1), sxhttp.MakeRequest(r2), "#<SxRequest:"},
+		{"response-writer", w1Obj, sxhttp.MakeResponseWriter(w1), sxhttp.MakeResponseWriter(w2), "#<SxResponseWriter:"},
+		{"response-writer-ptr", &w1Obj, sxhttp.MakeResponseWriter(w1), sxhttp.MakeResponseWriter(w2), "#<SxResponseWriter:"},
+		{"event-stream", es1, es1, es2, "#<SxEventStream:"},
+	}
+}
+
+func mustEventStream(r *http.Request) *sxhttp.SxEventStream {
+	es, err := sxhttp.NewEventStream(httptest.NewRecorder(), r, sxhtml.NewGenerator())
+	if err != nil {
+		panic(err)
+	}
+	return es
+}
+
+func TestObjectContract(t *testing.T) {
+	t.Parallel()
+	for _, tc := range makeObjectcases() {
+		t.Run(tc.name, func(t *testing.T) {
+			checkObjectContract(t, tc)
+		})
+	}
+}
+
+func checkObjectContract(t *testing.T, tc objectcase) {
+	t.Helper()
+	obj := tc.obj
+	if obj.IsNil() {
+		t.Error("IsNil() must be false")
+	}
+	if !obj.IsAtom() {
+		t.Error("IsAtom() must be true")
+	}
+	if !obj.IsTrue() {
+		t.Error("IsTrue() must be true")
+	}
+	if !obj.IsEqual(obj) {
+		t.Error("object must be equal to itself")
+	}
+	if !obj.IsEqual(tc.same) || !tc.same.IsEqual(obj) {
+		t.Errorf("object %v must be equal to %v", obj, tc.same)
+	}
+	if obj.IsEqual(tc.other) || tc.other.IsEqual(obj) {
+		t.Errorf("object %v must not be equal to %v", obj, tc.other)
+	}
+	if obj.IsEqual(sx.Nil()) {
+		t.Error("object must not be equal to nil")
+	}
+	if obj.IsEqual(sx.MakeString(obj.String())) {
+		t.Error("object must not be equal to its string representation")
+	}
+	if s := obj.String(); !strings.HasPrefix(s, tc.prefix) || !strings.HasSuffix(s, ">") {
+		t.Errorf("String() should be %q...>, but got %q", tc.prefix, s)
+	}
+	if s, gs := obj.String(), obj.GoString(); s != gs {
+		t.Errorf("String() and GoString() differ: %q / %q", s, gs)
+	}
+}
+
+func TestNilRequest(t *testing.T) {
+	t.Parallel()
+	var r *sxhttp.SxRequest
+	if !r.IsNil() {
+		t.Error("nil request must be nil")
+	}
+	if r.IsTrue() {
+		t.Error("nil request must not be true")
+	}
+	if !r.IsEqual(sx.Nil()) {
+		t.Error("nil request must be equal to nil")
+	}
+	if r.IsEqual(sxhttp.MakeRequest(httptest.NewRequest("GET", "/", nil))) {
+		t.Error("nil request must not be equal to a request")
+	}
+	if _, isRequest := sxhttp.GetRequest(r); isRequest {
+		t.Error("nil request must not be retrieved as a request")
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	t.Parallel()
+	names := map[string]bool{}
+	for _, b := range sxhttp.Builtins() {
+		if b.Name == "" {
+			t.Errorf("builtin without name: %v", b)
+		}
+		if names[b.Name] {
+			t.Errorf("duplicate builtin %q", b.Name)
+		}
+		names[b.Name] = true
+	}
+}
@@ -0,0 +1,208 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttptest
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtmls"
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/tags"
+)
+
+// ParseHTML parses HTML text into a list of SxHTML objects, one for every
+// top-level node. The document type declaration is ignored.
+//
+// The parser is meant for HTML generated by a program, e.g. by a
+// sxhtml.Generator. It does not implement the error recovery of the HTML
+// standard: end tags that were omitted are closed at the end of their
+// parent element.
+func ParseHTML(text string) (*sx.Pair, error) {
+	p := parser{text: text}
+	root := &htmls.Node{Type: htmls.ElementNode}
+	p.stack = []*htmls.Node{root}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	var lb sx.ListBuilder
+	for _, n := range root.Children {
+		obj, err := sxhtmls.ToSxHTML(n)
+		if err != nil {
+			return nil, err
+		}
+		lb.Add(obj)
+	}
+	return lb.List(), nil
+}
+
+type parser struct {
+	text  string
+	pos   int
+	stack []*htmls.Node
+}
+
+func (p *parser) parse() error {
+	for p.pos < len(p.text) {
+		rest := p.text[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return fmt.Errorf("unterminated comment at %d", p.pos)
+			}
+			p.add(&htmls.Node{Type: htmls.CommentNode, Data: rest[4 : 4+end]})
+			p.pos += 4 + end + 3
+		case strings.HasPrefix(rest, "<!"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return fmt.Errorf("unterminated declaration at %d", p.pos)
+			}
+			p.pos += end + 1
+		case strings.HasPrefix(rest, "</"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return fmt.Errorf("unterminated end tag at %d", p.pos)
+			}
+			p.closeTag(strings.ToLower(strings.TrimSpace(rest[2:end])))
+			p.pos += end + 1
+		case len(rest) > 1 && rest[0] == '<' && isNameChar(rest[1]):
+			if err := p.parseStartTag(); err != nil {
+				return err
+			}
+		default:
+			end := strings.IndexByte(rest[1:], '<')
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end++
+			}
+			p.add(&htmls.Node{Type: htmls.TextNode, Data: html.UnescapeString(rest[:end])})
+			p.pos += end
+		}
+	}
+	return nil
+}
+
+func (p *parser) add(n *htmls.Node) {
+	parent := p.stack[len(p.stack)-1]
+	if k := len(parent.Children); k > 0 && n.Type == htmls.TextNode && parent.Children[k-1].Type == htmls.TextNode {
+		parent.Children[k-1].Data += n.Data
+		return
+	}
+	parent.Children = append(parent.Children, n)
+}
+
+func (p *parser) closeTag(tag string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].Data == tag {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *parser) parseStartTag() error {
+	start := p.pos
+	p.pos++
+	n := &htmls.Node{Type: htmls.ElementNode, Data: strings.ToLower(p.readName())}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) {
+			return fmt.Errorf("unterminated start tag at %d", start)
+		}
+		switch c := p.text[p.pos]; {
+		case c == '>':
+			p.pos++
+			return p.openElement(n, false)
+		case c == '/' && strings.HasPrefix(p.text[p.pos:], "/>"):
+			p.pos += 2
+			return p.openElement(n, true)
+		default:
+			key := p.readName()
+			if key == "" {
+				return fmt.Errorf("invalid attribute at %d", p.pos)
+			}
+			n.Attributes = append(n.Attributes, htmls.Attribute{Key: strings.ToLower(key), Value: p.readAttributeValue()})
+		}
+	}
+}
+
+func (p *parser) openElement(n *htmls.Node, selfClosing bool) error {
+	p.add(n)
+	if selfClosing || tags.IsVoid(n.Data) {
+		return nil
+	}
+	if n.Data == "script" || n.Data == "style" {
+		end := strings.Index(strings.ToLower(p.text[p.pos:]), "</"+n.Data)
+		if end < 0 {
+			return fmt.Errorf("unterminated %s element", n.Data)
+		}
+		if end > 0 {
+			n.Children = append(n.Children, &htmls.Node{Type: htmls.RawNode, Data: p.text[p.pos : p.pos+end]})
+		}
+		p.pos += end
+		return nil
+	}
+	p.stack = append(p.stack, n)
+	return nil
+}
+
+func (p *parser) readName() string {
+	start := p.pos
+	for p.pos < len(p.text) && isNameChar(p.text[p.pos]) {
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *parser) readAttributeValue() string {
+	p.skipSpace()
+	if p.pos >= len(p.text) || p.text[p.pos] != '=' {
+		return ""
+	}
+	p.pos++
+	p.skipSpace()
+	if p.pos >= len(p.text) {
+		return ""
+	}
+	if q := p.text[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.text[p.pos+1:], q)
+		if end < 0 {
+			end = len(p.text) - p.pos - 1
+		}
+		val := p.text[p.pos+1 : p.pos+1+end]
+		p.pos = min(p.pos+end+2, len(p.text))
+		return html.UnescapeString(val)
+	}
+	start := p.pos
+	for p.pos < len(p.text) && !isSpace(p.text[p.pos]) && p.text[p.pos] != '>' {
+		p.pos++
+	}
+	return html.UnescapeString(p.text[start:p.pos])
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.text) && isSpace(p.text[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
+
+func isNameChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == ':'
+}
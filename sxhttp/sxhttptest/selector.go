@@ -0,0 +1,245 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttptest
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtml"
+)
+
+// Selector is a parsed subset of CSS selectors, to find elements in SxHTML.
+//
+// Supported are type selectors ("p"), id selectors ("#main"), class
+// selectors (".note"), attribute selectors ("[href]", "[type=submit]"), any
+// combination of them ("a.nav[href]"), the universal selector ("*"), and
+// the descendant combinator ("nav a").
+type Selector []compound
+
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// ParseSelector parses the given string as a selector.
+func ParseSelector(s string) (Selector, error) {
+	var result Selector
+	for part := range strings.FieldsSeq(s) {
+		c, err := parseCompound(part)
+		if err != nil {
+			return nil, fmt.Errorf("selector %q: %w", s, err)
+		}
+		result = append(result, c)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return result, nil
+}
+
+func parseCompound(s string) (compound, error) {
+	var c compound
+	end := strings.IndexAny(s, "#.[")
+	if end < 0 {
+		end = len(s)
+	}
+	if tag := s[:end]; tag != "*" {
+		c.tag = strings.ToLower(tag)
+	}
+	s = s[end:]
+	for s != "" {
+		switch s[0] {
+		case '#', '.':
+			end = strings.IndexAny(s[1:], "#.[")
+			if end < 0 {
+				end = len(s) - 1
+			}
+			name := s[1 : 1+end]
+			if name == "" {
+				return c, fmt.Errorf("missing name after %q", s[0])
+			}
+			if s[0] == '#' {
+				c.id = name
+			} else {
+				c.classes = append(c.classes, name)
+			}
+			s = s[1+end:]
+		case '[':
+			end = strings.IndexByte(s, ']')
+			if end < 0 {
+				return c, fmt.Errorf("missing ']'")
+			}
+			key, val, hasValue := strings.Cut(s[1:end], "=")
+			if key == "" {
+				return c, fmt.Errorf("missing attribute name")
+			}
+			c.attrs = append(c.attrs, attrSelector{
+				key:      strings.ToLower(key),
+				value:    strings.Trim(val, `"'`),
+				hasValue: hasValue,
+			})
+			s = s[end+1:]
+		default:
+			return c, fmt.Errorf("unexpected %q", s[0])
+		}
+	}
+	return c, nil
+}
+
+// Find returns all elements of the SxHTML object that match the selector,
+// in document order.
+func Find(obj sx.Object, sel Selector) []*sx.Pair {
+	var result []*sx.Pair
+	find(obj, sel, nil, &result)
+	return result
+}
+
+func find(obj sx.Object, sel Selector, ancestors []*sx.Pair, result *[]*sx.Pair) {
+	lst, isPair := sx.GetPair(obj)
+	if !isPair || lst == nil {
+		return
+	}
+	tag, isElem := elementTag(lst)
+	if !isElem {
+		// A list of elements, or a special form like a comment.
+		if _, isSym := sx.GetSymbol(lst.Car()); !isSym {
+			for elem := range lst.Values() {
+				find(elem, sel, ancestors, result)
+			}
+		}
+		return
+	}
+	if sel.matches(tag, lst, ancestors) {
+		*result = append(*result, lst)
+	}
+	ancestors = append(ancestors, lst)
+	for child := range children(lst) {
+		find(child, sel, ancestors, result)
+	}
+}
+
+func (sel Selector) matches(tag string, elem *sx.Pair, ancestors []*sx.Pair) bool {
+	last := len(sel) - 1
+	if !sel[last].matches(tag, elem) {
+		return false
+	}
+	// Match the remaining compounds against the ancestors, innermost first.
+	i := len(ancestors) - 1
+	for j := last - 1; j >= 0; j-- {
+		for ; i >= 0; i-- {
+			ancTag, _ := elementTag(ancestors[i])
+			if sel[j].matches(ancTag, ancestors[i]) {
+				break
+			}
+		}
+		if i < 0 {
+			return false
+		}
+		i--
+	}
+	return true
+}
+
+func (c *compound) matches(tag string, elem *sx.Pair) bool {
+	if c.tag != "" && c.tag != tag {
+		return false
+	}
+	attrs := sxhtml.GetAttributes(elem.Tail())
+	if c.id != "" {
+		if id, found := sxhtml.GetAttribute(attrs, "id"); !found || id != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		class, _ := sxhtml.GetAttribute(attrs, "class")
+		classes := strings.Fields(class)
+		for _, cl := range c.classes {
+			if !slices.Contains(classes, cl) {
+				return false
+			}
+		}
+	}
+	for _, as := range c.attrs {
+		val, found := sxhtml.GetAttribute(attrs, as.key)
+		if !found || (as.hasValue && val != as.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// elementTag returns the tag of an SxHTML element. Special forms, like
+// comments, are not elements.
+func elementTag(lst *sx.Pair) (string, bool) {
+	sym, isSymbol := sx.GetSymbol(lst.Car())
+	if !isSymbol {
+		return "", false
+	}
+	tag := sym.GetValue()
+	if strings.HasPrefix(tag, "@") {
+		return "", false
+	}
+	return tag, true
+}
+
+// children returns the content of an element.
+func children(elem *sx.Pair) iter.Seq[sx.Object] {
+	content := elem.Tail()
+	if sxhtml.GetAttributes(content) != nil {
+		content = content.Tail()
+	}
+	return content.Values()
+}
+
+// Text returns the text content of the SxHTML object, i.e. the concatenation
+// of all strings, without comments and raw HTML.
+func Text(obj sx.Object) string {
+	var sb strings.Builder
+	writeText(&sb, obj)
+	return sb.String()
+}
+
+func writeText(sb *strings.Builder, obj sx.Object) {
+	if s, isString := sx.GetString(obj); isString {
+		sb.WriteString(s.GetValue())
+		return
+	}
+	lst, isPair := sx.GetPair(obj)
+	if !isPair || lst == nil {
+		return
+	}
+	if _, isElem := elementTag(lst); isElem {
+		for child := range children(lst) {
+			writeText(sb, child)
+		}
+		return
+	}
+	if _, isSym := sx.GetSymbol(lst.Car()); !isSym {
+		for elem := range lst.Values() {
+			writeText(sb, elem)
+		}
+	}
+}
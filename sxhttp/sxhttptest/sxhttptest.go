@@ -0,0 +1,142 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package sxhttptest provides utilities to test Sx handlers, based on
+// net/http/httptest.
+package sxhttptest
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+// NewPair creates a request and a response recorder, together with their Sx
+// objects. They can be used as arguments to call a Sx handler procedure
+// (lambda (w r) ...) directly.
+func NewPair(method, target string, body io.Reader) (sxhttp.SxResponseWriter, *sxhttp.SxRequest, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, target, body)
+	return sxhttp.MakeResponseWriter(w), sxhttp.MakeRequest(r), w
+}
+
+// Serve lets the handler serve the request and returns the response for
+// further assertions.
+func Serve(t testing.TB, h http.Handler, r *http.Request) *Response {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return &Response{t: t, Recorder: w}
+}
+
+// Run calls the Sx procedure (lambda (w r) ...) for the request, like a
+// sxhttp.Handler does, and returns the response for further assertions.
+// Evaluation errors are logged to the test output.
+func Run(t testing.TB, makeEnv sxhttp.EnvironmentMaker, proc sxeval.Callable, r *http.Request) *Response {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(t.Output(), nil))
+	return Serve(t, sxhttp.NewHandler(makeEnv, proc).SetLogger(logger), r)
+}
+
+// Response is the recorded response of a handler. Its assertion methods
+// report failures to the test and return the response, so that they can be
+// chained.
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+	html     *sx.Pair
+	parsed   bool
+}
+
+// Status asserts the status code.
+func (resp *Response) Status(code int) *Response {
+	resp.t.Helper()
+	if got := resp.Recorder.Code; got != code {
+		resp.t.Errorf("expected status %d, but got %d", code, got)
+	}
+	return resp
+}
+
+// Header asserts the value of a header.
+func (resp *Response) Header(key, value string) *Response {
+	resp.t.Helper()
+	if got := resp.Recorder.Header().Get(key); got != value {
+		resp.t.Errorf("expected header %s: %q, but got %q", key, value, got)
+	}
+	return resp
+}
+
+// BodyContains asserts that the body contains the given string.
+func (resp *Response) BodyContains(s string) *Response {
+	resp.t.Helper()
+	if body := resp.Recorder.Body.String(); !strings.Contains(body, s) {
+		resp.t.Errorf("%q not found in body %q", s, body)
+	}
+	return resp
+}
+
+// HTML returns the body, parsed into a list of SxHTML objects. If the body
+// cannot be parsed, the test fails immediately.
+func (resp *Response) HTML() *sx.Pair {
+	resp.t.Helper()
+	if !resp.parsed {
+		html, err := ParseHTML(resp.Recorder.Body.String())
+		if err != nil {
+			resp.t.Fatalf("cannot parse body as HTML: %v", err)
+		}
+		resp.html, resp.parsed = html, true
+	}
+	return resp.html
+}
+
+// Find returns all elements of the HTML body that match the selector. An
+// invalid selector fails the test immediately.
+func (resp *Response) Find(selector string) []*sx.Pair {
+	resp.t.Helper()
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		resp.t.Fatal(err)
+	}
+	return Find(resp.HTML(), sel)
+}
+
+// Count asserts the number of elements that match the selector.
+func (resp *Response) Count(selector string, n int) *Response {
+	resp.t.Helper()
+	if got := len(resp.Find(selector)); got != n {
+		resp.t.Errorf("expected %d elements for %q, but got %d", n, selector, got)
+	}
+	return resp
+}
+
+// Text asserts the text content of the first element that matches the
+// selector.
+func (resp *Response) Text(selector, text string) *Response {
+	resp.t.Helper()
+	elems := resp.Find(selector)
+	if len(elems) == 0 {
+		resp.t.Errorf("no element found for %q", selector)
+		return resp
+	}
+	if got := strings.TrimSpace(Text(elems[0])); got != text {
+		resp.t.Errorf("expected text %q for %q, but got %q", text, selector, got)
+	}
+	return resp
+}
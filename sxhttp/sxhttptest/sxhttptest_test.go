@@ -0,0 +1,152 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttptest_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/sxwebs/sxhttp/sxhttptest"
+)
+
+func TestParseHTML(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+		exp  string
+	}{
+		{"empty", "", "()"},
+		{"text", "a &amp; b < c", `("a & b < c")`},
+		{"doctype", "<!DOCTYPE html><p>x</p>", `((p "x"))`},
+		{"attrs", `<a href="/x?a=1&amp;b=2" class=nav hidden>go</a>`, `((a ((href . "/x?a=1&b=2") (class . "nav") (hidden . "")) "go"))`},
+		{"void", `<p>a<br>b<img src='i.png'/></p>`, `((p "a" (br) "b" (img ((src . "i.png")))))`},
+		{"nested", `<ul><li>1</li><li>2</ul>`, `((ul (li "1") (li "2")))`},
+		{"comment", `<!--c--><b>x</b>`, `((@@@ "c") (b "x"))`},
+		{"script", `<script>if (a<b) {}</script>`, `((script (@H "if (a<b) {}")))`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sxhttptest.ParseHTML(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tc.exp {
+				t.Errorf("expected %s, but got %s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+	html, err := sxhttptest.ParseHTML(`<nav id="top"><a class="nav active" href="/">Home</a><a class="nav" href="/about">About</a></nav>` +
+		`<main><p>Text <a href="/more">more</a></p><form><input type="submit"></form></main>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases := []struct {
+		sel  string
+		exp  int
+		text string
+	}{
+		{"a", 3, "Home"},
+		{"a.nav", 2, "Home"},
+		{".nav.active", 1, "Home"},
+		{"#top a", 2, "Home"},
+		{"main a", 1, "more"},
+		{"nav p", 0, ""},
+		{"a[href=/about]", 1, "About"},
+		{"[type=submit]", 1, ""},
+		{"* p", 1, "Text more"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sel, func(t *testing.T) {
+			sel, errSel := sxhttptest.ParseSelector(tc.sel)
+			if errSel != nil {
+				t.Fatal(errSel)
+			}
+			elems := sxhttptest.Find(html, sel)
+			if len(elems) != tc.exp {
+				t.Fatalf("expected %d elements, but got %v", tc.exp, elems)
+			}
+			if len(elems) > 0 {
+				if got := sxhttptest.Text(elems[0]); got != tc.text {
+					t.Errorf("expected text %q, but got %q", tc.text, got)
+				}
+			}
+		})
+	}
+	for _, sel := range []string{"", "a[", "a#", "p..x", "[=x]"} {
+		if _, errSel := sxhttptest.ParseSelector(sel); errSel == nil {
+			t.Errorf("selector %q must be invalid", sel)
+		}
+	}
+}
+
+// recordingTB records failures, to test that assertions fail.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (*recordingTB) Helper() {}
+func (rt *recordingTB) Errorf(format string, args ...any) {
+	rt.errors = append(rt.errors, fmt.Sprintf(format, args...))
+}
+
+func TestResponse(t *testing.T) {
+	t.Parallel()
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, `<h1>Hello</h1><ul><li>a</li><li>b</li></ul>`)
+	})
+	sxhttptest.Serve(t, h, httptest.NewRequest("GET", "/", nil)).
+		Status(http.StatusOK).
+		Header("Content-Type", "text/html").
+		BodyContains("Hello").
+		Count("li", 2).
+		Text("h1", "Hello")
+
+	rt := &recordingTB{TB: t}
+	sxhttptest.Serve(rt, h, httptest.NewRequest("GET", "/", nil)).
+		Status(http.StatusNotFound).
+		Header("Content-Type", "text/plain").
+		BodyContains("Bye").
+		Count("li", 1).
+		Text("h1", "Bye").
+		Text("h2", "")
+	if len(rt.errors) != 6 {
+		t.Errorf("expected 6 failures, but got %q", rt.errors)
+	}
+}
+
+func TestNewPair(t *testing.T) {
+	t.Parallel()
+	w, r, rec := sxhttptest.NewPair("GET", "/path", nil)
+	if _, err := sxhttp.Write.Fn(nil, sx.Vector{w, sx.MakeString("ok")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Body.String(); got != "ok" {
+		t.Errorf("expected body %q, but got %q", "ok", got)
+	}
+	if got := r.GetValue().URL.Path; got != "/path" {
+		t.Errorf("expected path %q, but got %q", "/path", got)
+	}
+}
@@ -0,0 +1,588 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+)
+
+// ----- SxWebSocket ---------------------------------------------------------
+
+// SxWebSocket is the server side of a WebSocket connection (RFC 6455), seen
+// as a Sx object.
+//
+// The connection is closed, when the context of the upgraded request is
+// done, i.e. when the handler that upgraded the connection returns.
+type SxWebSocket struct {
+	conn    net.Conn
+	br      *bufio.Reader
+	rmx     sync.Mutex // only one goroutine reads frames
+	wmx     sync.Mutex
+	closed  atomic.Bool
+	maxSize int
+	stop    func() bool
+}
+
+// WebSocket opcodes.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// WebSocket close codes, as defined in RFC 6455.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// DefaultMaxMessageSize is the default maximum size of a received message.
+const DefaultMaxMessageSize = 1 << 20
+
+// closeTimeout is the maximum time to wait for the close frame of the client,
+// after the server sent its close frame.
+const closeTimeout = 3 * time.Second
+
+// wsGUID is used to compute the value of Sec-WebSocket-Accept.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// CloseError is returned, if the connection was closed, either by the client
+// or because of a protocol violation.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (ce *CloseError) Error() string {
+	if ce.Reason == "" {
+		return fmt.Sprintf("websocket closed: %d", ce.Code)
+	}
+	return fmt.Sprintf("websocket closed: %d %s", ce.Code, ce.Reason)
+}
+
+// ErrWebSocketClosed is returned, if a message is sent to a closed connection.
+var ErrWebSocketClosed = errors.New("websocket closed")
+
+// Upgrade performs the WebSocket handshake and takes over the connection of
+// the given response writer. If the request is not a valid WebSocket
+// request, an error status is sent and an error is returned.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*SxWebSocket, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket: method not GET")
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, http.StatusText(http.StatusUpgradeRequired), http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid key")
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, err
+	}
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	brw.WriteString("Sec-WebSocket-Accept: " + computeAcceptKey(key) + "\r\n\r\n")
+	if err = brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws := &SxWebSocket{conn: conn, br: brw.Reader, maxSize: DefaultMaxMessageSize}
+	ws.stop = context.AfterFunc(r.Context(), func() { ws.Close(CloseGoingAway, "") })
+	return ws, nil
+}
+
+func computeAcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerHasToken(h http.Header, key, token string) bool {
+	for _, val := range h.Values(key) {
+		for elem := range strings.SplitSeq(val, ",") {
+			if strings.EqualFold(strings.TrimSpace(elem), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetMaxMessageSize sets the maximum size of a received message.
+func (ws *SxWebSocket) SetMaxMessageSize(size int) *SxWebSocket { ws.maxSize = size; return ws }
+
+// IsNil returns true, if the object is a nil value.
+func (ws *SxWebSocket) IsNil() bool { return ws == nil }
+
+// IsAtom returns true if this object is an atomic object.
+func (*SxWebSocket) IsAtom() bool { return true }
+
+// IsTrue returns true if the websocket can be interpreted as a "true" value.
+func (ws *SxWebSocket) IsTrue() bool { return ws != nil }
+
+// IsEqual returns true, if this websocket is identical to the given object.
+func (ws *SxWebSocket) IsEqual(other sx.Object) bool {
+	if ws == nil {
+		return sx.IsNil(other)
+	}
+	otherWS, isWS := GetWebSocket(other)
+	return isWS && ws == otherWS
+}
+func (ws *SxWebSocket) String() string {
+	return fmt.Sprintf("#<SxWebSocket:%p>", ws)
+}
+
+// GoString returns the Go representation.
+func (ws *SxWebSocket) GoString() string { return ws.String() }
+
+// IsClosed returns true, if the connection is closed.
+func (ws *SxWebSocket) IsClosed() bool { return ws.closed.Load() }
+
+// ReadText returns the next text message. Ping frames are answered
+// automatically. If the client closes the connection, a *CloseError is
+// returned. Binary messages are not supported and close the connection.
+func (ws *SxWebSocket) ReadText() (string, error) {
+	op, data, err := ws.readMessage()
+	if err != nil {
+		return "", err
+	}
+	if op != wsText {
+		return "", ws.fail(CloseUnsupportedData, "binary messages not supported")
+	}
+	return string(data), nil
+}
+
+// WriteText sends a text message.
+func (ws *SxWebSocket) WriteText(s string) error {
+	if ws.IsClosed() {
+		return ErrWebSocketClosed
+	}
+	return ws.writeFrame(wsText, []byte(s))
+}
+
+// Close sends a close frame with the given code and reason, waits for the
+// close frame of the client, and closes the connection (RFC 6455, 7.1.1).
+// Messages that arrive in the meantime are discarded. If the client does not
+// answer within a few seconds, the connection is closed nevertheless.
+func (ws *SxWebSocket) Close(code int, reason string) error {
+	return ws.close(code, reason, true)
+}
+
+// close sends the close frame, and closes the connection. If await is true,
+// the close frame of the client is awaited before. If the close frame was
+// already sent, the connection is closed only if await is false.
+func (ws *SxWebSocket) close(code int, reason string, await bool) error {
+	if ws.closed.Swap(true) {
+		if !await {
+			ws.conn.Close()
+		}
+		return nil
+	}
+	ws.stop()
+	var payload []byte
+	if code != CloseNoStatus {
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+	err := ws.writeFrame(wsClose, payload)
+	if await && err == nil && !ws.awaitClose() {
+		return nil
+	}
+	if errClose := ws.conn.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// awaitClose discards all frames, until the close frame of the client
+// arrives, an error occurs, or closeTimeout is over. If another goroutine is
+// reading, false is returned: that goroutine will receive the close frame or
+// the timeout, and then closes the connection.
+func (ws *SxWebSocket) awaitClose() bool {
+	ws.conn.SetReadDeadline(time.Now().Add(closeTimeout))
+	if !ws.rmx.TryLock() {
+		return false
+	}
+	defer ws.rmx.Unlock()
+	for {
+		if _, op, _, err := ws.readFrame(); err != nil || op == wsClose {
+			return true
+		}
+	}
+}
+
+// fail closes the connection because of an error, without waiting for the
+// client.
+func (ws *SxWebSocket) fail(code int, reason string) error {
+	ws.close(code, reason, false)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+func (ws *SxWebSocket) readMessage() (byte, []byte, error) {
+	ws.rmx.Lock()
+	defer ws.rmx.Unlock()
+	if ws.IsClosed() {
+		return 0, nil, ErrWebSocketClosed
+	}
+	var msgOp byte
+	var msg []byte
+	inMessage := false
+	for {
+		fin, op, payload, err := ws.readFrame()
+		if err != nil {
+			if ce, isClose := errors.AsType[*CloseError](err); isClose {
+				return 0, nil, ws.fail(ce.Code, ce.Reason)
+			}
+			ws.closed.Store(true)
+			ws.conn.Close()
+			return 0, nil, err
+		}
+		if op != wsClose && ws.IsClosed() {
+			continue // Close awaits the close frame of the client
+		}
+		switch op {
+		case wsPing:
+			if err = ws.writeFrame(wsPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			if len(payload) == 1 {
+				return 0, nil, ws.fail(CloseProtocolError, "invalid close payload")
+			}
+			ce := parseClosePayload(payload)
+			ws.close(ce.Code, "", false)
+			return 0, nil, ce
+		case wsText, wsBinary:
+			if inMessage {
+				return 0, nil, ws.fail(CloseProtocolError, "unfinished message")
+			}
+			msgOp, msg, inMessage = op, payload, true
+		case wsContinuation:
+			if !inMessage {
+				return 0, nil, ws.fail(CloseProtocolError, "unexpected continuation")
+			}
+			if len(msg)+len(payload) > ws.maxSize {
+				return 0, nil, ws.fail(CloseMessageTooBig, "")
+			}
+			msg = append(msg, payload...)
+		default:
+			return 0, nil, ws.fail(CloseProtocolError, "unknown opcode")
+		}
+		if fin {
+			if msgOp == wsText && !utf8.Valid(msg) {
+				return 0, nil, ws.fail(CloseInvalidPayload, "")
+			}
+			return msgOp, msg, nil
+		}
+	}
+}
+
+func parseClosePayload(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{Code: CloseNoStatus}
+	}
+	return &CloseError{Code: int(binary.BigEndian.Uint16(payload)), Reason: string(payload[2:])}
+}
+
+// readFrame reads one frame. Frames from a client must be masked.
+func (ws *SxWebSocket) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var hdr [8]byte
+	if _, err = io.ReadFull(ws.br, hdr[:2]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = hdr[0]&0x80 != 0, hdr[0]&0x0f
+	if hdr[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "reserved bits set"}
+	}
+	if hdr[1]&0x80 == 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "frame not masked"}
+	}
+	length := uint64(hdr[1] & 0x7f)
+	switch length {
+	case 126:
+		if _, err = io.ReadFull(ws.br, hdr[:2]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(hdr[:2]))
+	case 127:
+		if _, err = io.ReadFull(ws.br, hdr[:8]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(hdr[:8])
+	}
+	if op >= wsClose && (!fin || length > 125) {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "invalid control frame"}
+	}
+	if length > uint64(ws.maxSize) {
+		return false, 0, nil, &CloseError{Code: CloseMessageTooBig}
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(ws.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame sends an unmasked frame, as required for a server.
+func (ws *SxWebSocket) writeFrame(op byte, payload []byte) error {
+	buf := make([]byte, 0, len(payload)+10)
+	buf = append(buf, 0x80|op)
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, byte(n))
+	case n <= 0xffff:
+		buf = append(buf, 126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, 127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	buf = append(buf, payload...)
+	ws.wmx.Lock()
+	defer ws.wmx.Unlock()
+	_, err := ws.conn.Write(buf)
+	return err
+}
+
+// GetWebSocket returns the given sx.Object as a SxWebSocket, if possible.
+func GetWebSocket(obj sx.Object) (*SxWebSocket, bool) {
+	if sx.IsNil(obj) {
+		return nil, false
+	}
+	ws, ok := obj.(*SxWebSocket)
+	return ws, ok
+}
+
+// GetBuiltinWebSocket returns the given sx.Object as a SxWebSocket. If this is
+// not possible, an error is returned.
+//
+// This function can be used as a helper function to implement sxeval.Builtin.
+func GetBuiltinWebSocket(arg sx.Object, pos int) (*SxWebSocket, error) {
+	if ws, isWS := GetWebSocket(arg); isWS {
+		return ws, nil
+	}
+	return nil, fmt.Errorf("argument %d is not a websocket, but %T/%v", pos+1, arg, arg)
+}
+
+// ----- Builtins ------------------------------------------------------------
+
+// Symbols for message formats of websocket builtins.
+var (
+	SymFormatSx   = sx.MakeSymbol("sx")
+	SymFormatJSON = sx.MakeSymbol("json")
+	SymFormatText = sx.MakeSymbol("text")
+)
+
+func getBuiltinFormat(args sx.Vector, pos int) (*sx.Symbol, error) {
+	if len(args) <= pos {
+		return SymFormatSx, nil
+	}
+	sym, isSymbol := sx.GetSymbol(args[pos])
+	if !isSymbol || !(sym.IsEqual(SymFormatSx) || sym.IsEqual(SymFormatJSON) || sym.IsEqual(SymFormatText)) {
+		return nil, fmt.Errorf("argument %d is not a message format (sx, json, text), but %T/%v", pos+1, args[pos], args[pos])
+	}
+	return sym, nil
+}
+
+// WebSocketUpgrade is a builtin that upgrades the request to a WebSocket
+// connection: (websocket-upgrade w r).
+var WebSocketUpgrade = sxeval.Builtin{
+	Name:     "websocket-upgrade",
+	MinArity: 2,
+	MaxArity: 2,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		w, err := GetBuiltinResponseWriter(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		r, err := GetBuiltinRequest(args[1], 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		ws, err := Upgrade(w.GetValue(), r.GetValue())
+		if err != nil {
+			return sx.Nil(), err
+		}
+		return ws, nil
+	},
+}
+
+// WebSocketSend is a builtin that sends an object as a text message:
+// (websocket-send ws obj format). Format is one of the symbols "sx"
+// (default, the printed s-expression), "json", or "text" (obj must be a
+// string).
+var WebSocketSend = sxeval.Builtin{
+	Name:     "websocket-send",
+	MinArity: 2,
+	MaxArity: 3,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		ws, err := GetBuiltinWebSocket(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		format, err := getBuiltinFormat(args, 2)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		var text string
+		switch {
+		case format.IsEqual(SymFormatJSON):
+			val, errJSON := ToJSON(args[1])
+			if errJSON != nil {
+				return sx.Nil(), errJSON
+			}
+			data, errJSON := json.Marshal(val)
+			if errJSON != nil {
+				return sx.Nil(), errJSON
+			}
+			text = string(data)
+		case format.IsEqual(SymFormatText):
+			s, isString := sx.GetString(args[1])
+			if !isString {
+				return sx.Nil(), fmt.Errorf("argument 2 is not a string, but %T/%v", args[1], args[1])
+			}
+			text = s.GetValue()
+		default:
+			text = args[1].String()
+		}
+		return sx.Nil(), ws.WriteText(text)
+	},
+}
+
+// WebSocketReceive is a builtin that receives the next text message:
+// (websocket-receive ws format). Format is one of the symbols "sx" (default,
+// the message is read as a s-expression), "json", or "text" (the message is
+// returned as a string). If the connection was closed, nil is returned.
+var WebSocketReceive = sxeval.Builtin{
+	Name:     "websocket-receive",
+	MinArity: 1,
+	MaxArity: 2,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		ws, err := GetBuiltinWebSocket(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		format, err := getBuiltinFormat(args, 1)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		text, err := ws.ReadText()
+		if err != nil {
+			if ws.IsClosed() {
+				return sx.Nil(), nil
+			}
+			return sx.Nil(), err
+		}
+		switch {
+		case format.IsEqual(SymFormatJSON):
+			return ParseJSON(strings.NewReader(text))
+		case format.IsEqual(SymFormatText):
+			return sx.MakeString(text), nil
+		}
+		return sxreader.MakeReader(strings.NewReader(text)).Read()
+	},
+}
+
+// WebSocketClose is a builtin that closes the connection:
+// (websocket-close ws code reason). Code defaults to 1000 (normal closure).
+var WebSocketClose = sxeval.Builtin{
+	Name:     "websocket-close",
+	MinArity: 1,
+	MaxArity: 3,
+	Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+		ws, err := GetBuiltinWebSocket(args[0], 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		code, reason := int64(CloseNormal), ""
+		if len(args) > 1 {
+			if code, err = getBuiltinInt(args[1], 1); err != nil {
+				return sx.Nil(), err
+			}
+			if code < 1000 || code > 4999 {
+				return sx.Nil(), fmt.Errorf("invalid close code: %d", code)
+			}
+		}
+		if len(args) > 2 {
+			if reason, err = getBuiltinKey(args[2], 2); err != nil {
+				return sx.Nil(), err
+			}
+		}
+		return sx.Nil(), ws.Close(int(code), reason)
+	},
+}
+
+// WebSocketClosed is a builtin that returns true, if the connection is
+// closed: (websocket-closed? ws).
+var WebSocketClosed = sxeval.Builtin{
+	Name:     "websocket-closed?",
+	MinArity: 1,
+	MaxArity: 1,
+	Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+		ws, err := GetBuiltinWebSocket(arg, 0)
+		if err != nil {
+			return sx.Nil(), err
+		}
+		return sx.MakeBoolean(ws.IsClosed()), nil
+	},
+}
@@ -0,0 +1,249 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxhttp_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+// wsClient is a minimal WebSocket client for testing.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: example.com\r\n"+
+		"Upgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101, but got %d", resp.StatusCode)
+	}
+	// Example value from RFC 6455, section 1.3.
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("wrong accept key: %q", got)
+	}
+	return &wsClient{t: t, conn: conn, br: br}
+}
+
+func (c *wsClient) send(op byte, fin bool, payload string) {
+	c.t.Helper()
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	buf := []byte{b0, 0x80 | byte(len(payload))}
+	buf = append(buf, mask[:]...)
+	for i := range len(payload) {
+		buf = append(buf, payload[i]^mask[i%4])
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *wsClient) receive() (byte, string) {
+	c.t.Helper()
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	if hdr[1]&0x80 != 0 {
+		c.t.Error("server frames must not be masked")
+	}
+	length := int(hdr[1] & 0x7f)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return hdr[0] & 0x0f, string(payload)
+}
+
+func TestWebSocket(t *testing.T) {
+	t.Parallel()
+	done := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := sxhttp.Upgrade(w, r)
+		if err != nil {
+			done <- err
+			return
+		}
+		for {
+			obj, errRecv := sxhttp.WebSocketReceive.Fn(nil, sx.Vector{ws}, nil)
+			if errRecv != nil || sx.IsNil(obj) {
+				done <- errRecv
+				return
+			}
+			reply := sx.MakeList(sx.MakeSymbol("echo"), obj)
+			if _, errRecv = sxhttp.WebSocketSend.Fn(nil, sx.Vector{ws, reply, sxhttp.SymFormatJSON}, nil); errRecv != nil {
+				done <- errRecv
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+	c := dialWebSocket(t, srv)
+
+	c.send(0x1, true, `(a "b" 3)`)
+	if op, got := c.receive(); op != 0x1 || got != `["echo",["a","b",3]]` {
+		t.Errorf("unexpected message %d/%q", op, got)
+	}
+
+	c.send(0x9, true, "hello")
+	if op, got := c.receive(); op != 0xA || got != "hello" {
+		t.Errorf("expected pong, but got %d/%q", op, got)
+	}
+
+	c.send(0x1, false, "(frag")
+	c.send(0x0, true, "ment)")
+	if op, got := c.receive(); op != 0x1 || got != `["echo",["fragment"]]` {
+		t.Errorf("unexpected message %d/%q", op, got)
+	}
+
+	c.send(0x8, true, "\x03\xe8bye")
+	if op, got := c.receive(); op != 0x8 || got != "\x03\xe8" {
+		t.Errorf("expected close frame, but got %d/%q", op, got)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWebSocketProtocolError(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := sxhttp.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		ws.ReadText()
+	}))
+	defer srv.Close()
+	c := dialWebSocket(t, srv)
+
+	io.WriteString(c.conn, "\x81\x03abc") // unmasked frame from client
+	op, got := c.receive()
+	if op != 0x8 || !strings.HasPrefix(got, "\x03\xea") {
+		t.Errorf("expected close frame with code 1002, but got %d/%q", op, got)
+	}
+}
+
+func TestWebSocketInvalidClose(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := sxhttp.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		ws.ReadText()
+	}))
+	defer srv.Close()
+	c := dialWebSocket(t, srv)
+
+	c.send(0x8, true, "\x03") // close payload of length 1
+	op, got := c.receive()
+	if op != 0x8 || !strings.HasPrefix(got, "\x03\xea") {
+		t.Errorf("expected close frame with code 1002, but got %d/%q", op, got)
+	}
+}
+
+func TestWebSocketClose(t *testing.T) {
+	t.Parallel()
+	done := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := sxhttp.Upgrade(w, r)
+		if err != nil {
+			done <- err
+			return
+		}
+		done <- ws.Close(sxhttp.CloseNormal, "bye")
+	}))
+	defer srv.Close()
+	c := dialWebSocket(t, srv)
+
+	if op, got := c.receive(); op != 0x8 || got != "\x03\xe8bye" {
+		t.Fatalf("expected close frame, but got %d/%q", op, got)
+	}
+	c.send(0x1, true, "late")
+	select {
+	case err := <-done:
+		t.Fatalf("close returned before the client answered: %v", err)
+	default:
+	}
+	c.send(0x8, true, "\x03\xe8")
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if n, err := c.br.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("expected closed connection, but got %d/%v", n, err)
+	}
+}
+
+func TestWebSocketBadRequest(t *testing.T) {
+	t.Parallel()
+	r := httptest.NewRequest("GET", "/ws", nil)
+	w := httptest.NewRecorder()
+	if _, err := sxhttp.Upgrade(w, r); err == nil {
+		t.Error("upgrade of a plain request must fail")
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, but got %d", w.Code)
+	}
+}
+
+func TestWebSocketObject(t *testing.T) {
+	t.Parallel()
+	conns := make(chan *sxhttp.SxWebSocket, 2)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ws, err := sxhttp.Upgrade(w, r); err == nil {
+			conns <- ws
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	dialWebSocket(t, srv)
+	dialWebSocket(t, srv)
+	ws1, ws2 := <-conns, <-conns
+	checkObjectContract(t, objectcase{"websocket", ws1, ws1, ws2, "#<SxWebSocket:"})
+}
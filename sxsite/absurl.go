@@ -0,0 +1,258 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/webs/site"
+)
+
+// Origin determines the scheme and host of absolute URLs, e.g. for e-mails,
+// feeds, or OpenGraph tags.
+//
+// If a canonical origin is configured, it is always used. Otherwise the
+// origin is taken from the request. The headers "Forwarded" (RFC 7239) and
+// "X-Forwarded-Proto" / "X-Forwarded-Host" are only respected, if the request
+// was sent by a trusted proxy. Since a proxy appends its values to those of
+// the client, the last value is used.
+//
+// Since the host of a request is chosen by the client, it must be one of the
+// allowed hosts. Otherwise a forged "Host" header would result in links to
+// another site.
+type Origin struct {
+	canonical string
+	trusted   []netip.Prefix
+	allowed   []string
+}
+
+// NewOrigin creates a new origin. If canonical is not empty, e.g.
+// "https://example.com", it is used for all absolute URLs. Otherwise, the
+// hosts of requests must be allowed by SetAllowedHosts.
+func NewOrigin(canonical string) *Origin {
+	return &Origin{canonical: strings.TrimSuffix(canonical, "/")}
+}
+
+// SetTrustedProxies sets the addresses of proxies, whose forwarding headers
+// are respected.
+func (o *Origin) SetTrustedProxies(prefixes ...netip.Prefix) *Origin {
+	o.trusted = prefixes
+	return o
+}
+
+// SetAllowedHosts sets the hosts, e.g. "example.com" or "localhost:8080",
+// that may be taken from a request, if there is no canonical origin.
+func (o *Origin) SetAllowedHosts(hosts ...string) *Origin {
+	o.allowed = make([]string, len(hosts))
+	for i, host := range hosts {
+		o.allowed[i] = strings.ToLower(host)
+	}
+	return o
+}
+
+// FromRequest returns the origin ("scheme://host") for the request. The
+// request may be nil, if a canonical origin is configured.
+func (o *Origin) FromRequest(r *http.Request) (string, error) {
+	if o.canonical != "" {
+		return o.canonical, nil
+	}
+	if len(o.allowed) == 0 {
+		return "", fmt.Errorf("no canonical origin and no allowed hosts")
+	}
+	if r == nil {
+		return "", fmt.Errorf("no canonical origin and no request")
+	}
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if o.isTrusted(r.RemoteAddr) {
+		if proto, fwdHost := parseForwarded(r.Header.Values("Forwarded")); proto != "" || fwdHost != "" {
+			scheme, host = orDefault(proto, scheme), orDefault(fwdHost, host)
+		} else {
+			scheme = orDefault(lastValue(r.Header.Values("X-Forwarded-Proto")), scheme)
+			host = orDefault(lastValue(r.Header.Values("X-Forwarded-Host")), host)
+		}
+	}
+	scheme = strings.ToLower(scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("invalid scheme %q", scheme)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@\\ \t\r\n") {
+		return "", fmt.Errorf("invalid host %q", host)
+	}
+	host = strings.ToLower(host)
+	if !slices.Contains(o.allowed, host) {
+		return "", fmt.Errorf("host %q is not allowed", host)
+	}
+	return scheme + "://" + host, nil
+}
+
+func (o *Origin) isTrusted(remoteAddr string) bool {
+	if len(o.trusted) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range o.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseForwarded returns proto and host of the last element of the
+// "Forwarded" header values.
+func parseForwarded(values []string) (proto, host string) {
+	elem := lastValue(values)
+	for pair := range strings.SplitSeq(elem, ";") {
+		key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		val = strings.Trim(val, `"`)
+		switch strings.ToLower(key) {
+		case "proto":
+			proto = val
+		case "host":
+			host = val
+		}
+	}
+	return proto, host
+}
+
+// lastValue returns the last element of comma-separated header values.
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	last := values[len(values)-1]
+	if pos := strings.LastIndexByte(last, ','); pos >= 0 {
+		last = last[pos+1:]
+	}
+	return strings.TrimSpace(last)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// absoluteURL places the origin before the URL path. An URL that is already
+// absolute, i.e. that has a scheme or a host, is returned unchanged.
+func absoluteURL(origin, path string) string {
+	if u, err := url.Parse(path); err == nil && (u.Scheme != "" || u.Host != "") {
+		return path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return origin + path
+}
+
+// getOrigin returns the origin for the argument, which is a request or nil.
+func getOrigin(o *Origin, arg sx.Object) (string, error) {
+	var r *http.Request
+	if !sx.IsNil(arg) {
+		sxr, err := sxhttp.GetBuiltinRequest(arg, 0)
+		if err != nil {
+			return "", err
+		}
+		r = sxr.GetValue()
+	}
+	return o.FromRequest(r)
+}
+
+// MakeAbsoluteURLBuiltin returns a builtin that provides the
+// (absolute-url r path) function. It returns the absolute URL of the path,
+// e.g. a result of "make-url". The request r may be nil, if the origin has a
+// canonical value.
+func MakeAbsoluteURLBuiltin(o *Origin) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "absolute-url",
+		MinArity: 2,
+		MaxArity: 2,
+		TestPure: sxeval.AssertPure,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			origin, err := getOrigin(o, args[0])
+			if err != nil {
+				return nil, err
+			}
+			path, err := sxbuiltins.GetString(args[1], 1)
+			if err != nil {
+				return nil, err
+			}
+			return sx.MakeString(absoluteURL(origin, path.GetValue())), nil
+		},
+	}
+}
+
+// MakeAbsoluteURLForBuiltin returns a builtin that provides the
+// (absolute-url-for r node-id args...) function. Except for the request r,
+// which may be nil if the origin has a canonical value, the arguments are
+// the same as for "url-for":
+//
+//	(absolute-url-for r "user" 42 "#top")
+func MakeAbsoluteURLForBuiltin(st *site.Site, o *Origin) *sxeval.Builtin {
+	return makeAbsoluteURLForBuiltin(o, func(args sx.Vector) (string, error) {
+		obj, err := urlFor(st, args, 1)
+		if err != nil {
+			return "", err
+		}
+		path, _ := sx.GetString(obj)
+		return path.GetValue(), nil
+	})
+}
+
+// makeAbsoluteURLForBuiltin returns the builtin "absolute-url-for", where
+// urlFor returns the URL path for the arguments that follow the request.
+func makeAbsoluteURLForBuiltin(o *Origin, urlFor func(sx.Vector) (string, error)) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "absolute-url-for",
+		MinArity: 2,
+		MaxArity: -1,
+		TestPure: sxeval.AssertPure,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			origin, err := getOrigin(o, args[0])
+			if err != nil {
+				return nil, err
+			}
+			path, err := urlFor(args)
+			if err != nil {
+				return nil, err
+			}
+			return sx.MakeString(absoluteURL(origin, path)), nil
+		},
+	}
+}
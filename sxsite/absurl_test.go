@@ -0,0 +1,177 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestOriginFromRequest(t *testing.T) {
+	t.Parallel()
+	o := NewOrigin("").
+		SetTrustedProxies(netip.MustParsePrefix("10.0.0.0/8")).
+		SetAllowedHosts("example.com", "WWW.example.org", "a.example")
+	testcases := []struct {
+		name   string
+		remote string
+		header [][2]string
+		exp    string
+	}{
+		{"direct", "192.0.2.1:1234", nil, "http://example.com"},
+		{"untrusted", "192.0.2.1:1234", [][2]string{{"X-Forwarded-Proto", "https"}}, "http://example.com"},
+		{"x-forwarded", "10.1.2.3:1234", [][2]string{{"X-Forwarded-Proto", "https"}, {"X-Forwarded-Host", "www.example.org"}}, "https://www.example.org"},
+		{"x-forwarded-last", "10.1.2.3:1234", [][2]string{{"X-Forwarded-Proto", "http, https"}}, "https://example.com"},
+		{"forwarded", "10.1.2.3:1234", [][2]string{{"Forwarded", `for=192.0.2.7;proto=http, for=192.0.2.8;proto=https;host="a.example"`}}, "https://a.example"},
+		{"mapped", "[::ffff:10.0.0.1]:1234", [][2]string{{"X-Forwarded-Proto", "https"}}, "https://example.com"},
+		{"case", "10.1.2.3:1234", [][2]string{{"X-Forwarded-Host", "www.EXAMPLE.org"}}, "http://www.example.org"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			for _, h := range tc.header {
+				r.Header.Add(h[0], h[1])
+			}
+			got, err := o.FromRequest(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.exp {
+				t.Errorf("expected %q, but got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestOriginError(t *testing.T) {
+	t.Parallel()
+	o := NewOrigin("").
+		SetTrustedProxies(netip.MustParsePrefix("10.0.0.0/8")).
+		SetAllowedHosts("example.com")
+	if _, err := o.FromRequest(nil); err == nil {
+		t.Error("error expected for missing request")
+	}
+	if _, err := NewOrigin("").FromRequest(httptest.NewRequest("GET", "/", nil)); err == nil {
+		t.Error("error expected without allowed hosts")
+	}
+	testcases := []struct {
+		name   string
+		remote string
+		host   string
+		header [2]string
+	}{
+		{"scheme", "10.0.0.1:1234", "example.com", [2]string{"X-Forwarded-Proto", "gopher"}},
+		{"host", "192.0.2.1:1234", "evil.example", [2]string{}},
+		{"host-query", "192.0.2.1:1234", "example.com?x", [2]string{}},
+		{"host-fragment", "192.0.2.1:1234", "example.com#x", [2]string{}},
+		{"host-user", "192.0.2.1:1234", "user@example.com", [2]string{}},
+		{"forwarded-host", "10.0.0.1:1234", "example.com", [2]string{"X-Forwarded-Host", "evil.example"}},
+		{"forwarded", "10.0.0.1:1234", "example.com", [2]string{"Forwarded", "host=evil.example"}},
+		{"forwarded-path", "10.0.0.1:1234", "example.com", [2]string{"X-Forwarded-Host", "example.com/x"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			r.Host = tc.host
+			if tc.header[0] != "" {
+				r.Header.Set(tc.header[0], tc.header[1])
+			}
+			if got, err := o.FromRequest(r); err == nil {
+				t.Errorf("error expected, but got %q", got)
+			}
+		})
+	}
+}
+
+func TestOriginCanonical(t *testing.T) {
+	t.Parallel()
+	got, err := NewOrigin("https://example.org/").FromRequest(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://example.org" {
+		t.Errorf("unexpected origin %q", got)
+	}
+	if got = absoluteURL(got, "docs/"); got != "https://example.org/docs/" {
+		t.Errorf("unexpected URL %q", got)
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		path string
+		exp  string
+	}{
+		{"/docs/", "https://example.org/docs/"},
+		{"docs/", "https://example.org/docs/"},
+		{"/go?u=http://x", "https://example.org/go?u=http://x"},
+		{"/a#b://c", "https://example.org/a#b://c"},
+		{"https://example.com/x", "https://example.com/x"},
+		{"//cdn.example/x", "//cdn.example/x"},
+		{"mailto:info@example.org", "mailto:info@example.org"},
+	}
+	for _, tc := range testcases {
+		if got := absoluteURL("https://example.org", tc.path); got != tc.exp {
+			t.Errorf("%q: expected %q, but got %q", tc.path, tc.exp, got)
+		}
+	}
+}
+
+func TestAbsoluteURLBuiltins(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	canonical := NewOrigin("https://example.org")
+	fromRequest := NewOrigin("").SetAllowedHosts("example.com")
+	request := sxhttp.MakeRequest(httptest.NewRequest("GET", "/docs/", nil))
+	testcases := []struct {
+		name    string
+		builtin *sxeval.Builtin
+		args    sx.Vector
+		exp     string
+	}{
+		{"url", MakeAbsoluteURLBuiltin(canonical), sx.Vector{sx.Nil(), sx.MakeString("/docs/style.css")}, "https://example.org/docs/style.css"},
+		{"url-request", MakeAbsoluteURLBuiltin(fromRequest), sx.Vector{request, sx.MakeString("/docs/")}, "http://example.com/docs/"},
+		{"url-no-request", MakeAbsoluteURLBuiltin(fromRequest), sx.Vector{sx.Nil(), sx.MakeString("/docs/")}, ""},
+		{"url-no-string", MakeAbsoluteURLBuiltin(canonical), sx.Vector{sx.Nil(), sx.Int64(1)}, ""},
+		{"url-for", MakeAbsoluteURLForBuiltin(st, canonical), sx.Vector{sx.Nil(), sx.MakeString("user"), sx.Int64(7), sx.MakeString("#top")}, "https://example.org/docs/users/7/#top"},
+		{"url-for-request", MakeAbsoluteURLForBuiltin(st, fromRequest), sx.Vector{request, sx.MakeString("intro")}, "http://example.com/docs/intro/"},
+		{"url-for-unknown", MakeAbsoluteURLForBuiltin(st, canonical), sx.Vector{sx.Nil(), sx.MakeString("none")}, ""},
+		{"url-for-no-request", MakeAbsoluteURLForBuiltin(st, canonical), sx.Vector{sx.MakeString("intro"), sx.MakeString("intro")}, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.builtin.Fn(nil, tc.args, nil)
+			if tc.exp == "" {
+				if err == nil {
+					t.Errorf("error expected, but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s, isString := sx.GetString(got); !isString || s.GetValue() != tc.exp {
+				t.Errorf("expected %q, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
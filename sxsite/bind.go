@@ -0,0 +1,124 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"net/http"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/webs/site"
+)
+
+// Binder binds all builtins of a site in a binding, optionally together with
+// the builtins for absolute and localised URLs, and those of package sxhttp.
+//
+//	err := sxsite.NewBinder(st).SetPrefix("site:").SetHTTP().Bind(bind)
+type Binder struct {
+	site    *site.Site
+	prefix  string
+	origin  *Origin
+	i18n    *I18N
+	locale  string
+	http    bool
+	makeGen func(*http.Request) *sxhtml.Generator
+}
+
+// NewBinder creates a new binder for the builtins of the given site.
+func NewBinder(st *site.Site) *Binder { return &Binder{site: st} }
+
+// SetPrefix sets the prefix of all bound names, to avoid clashes with other
+// definitions.
+func (b *Binder) SetPrefix(prefix string) *Binder { b.prefix = prefix; return b }
+
+// SetOrigin adds the builtins "absolute-url" and "absolute-url-for", which
+// use the given origin.
+func (b *Binder) SetOrigin(o *Origin) *Binder { b.origin = o; return b }
+
+// SetI18N adds the builtins "current-locale" and "site-alternates". The
+// builtins "url-for" and "absolute-url-for" return the URLs of the nodes in
+// the given locale.
+func (b *Binder) SetI18N(in *I18N, locale string) *Binder {
+	b.i18n, b.locale = in, locale
+	return b
+}
+
+// SetHTTP adds the builtins of sxhttp.Builtins().
+func (b *Binder) SetHTTP() *Binder { b.http = true; return b }
+
+// SetGenerator adds the builtins that render SxHTML with a generator, which
+// is created for every request: "response-write-negotiated" and "sse-start".
+func (b *Binder) SetGenerator(makeGen func(*http.Request) *sxhtml.Generator) *Binder {
+	b.makeGen = makeGen
+	return b
+}
+
+// Builtins returns all builtins of the binder.
+func (b *Binder) Builtins() []*sxeval.Builtin {
+	st := b.site
+	urlFor := MakeURLForBuiltin(st)
+	if b.i18n != nil {
+		urlFor = MakeLocaleURLForBuiltin(b.i18n, b.locale)
+	}
+	result := []*sxeval.Builtin{
+		urlFor,
+		MakeMakeURLBuiltin(st),
+		MakeCurrentNodeBuiltin(st),
+		MakeNodeParentsBuiltin(st),
+		MakeNodeChildrenBuiltin(st),
+		MakeNodeTitleBuiltin(st),
+		MakeNodeVisibleBuiltin(st),
+	}
+	if b.origin != nil {
+		absURLFor := MakeAbsoluteURLForBuiltin(st, b.origin)
+		if b.i18n != nil {
+			absURLFor = MakeLocaleAbsoluteURLForBuiltin(b.i18n, b.locale, b.origin)
+		}
+		result = append(result, MakeAbsoluteURLBuiltin(b.origin), absURLFor)
+	}
+	if b.i18n != nil {
+		result = append(result, MakeCurrentLocaleBuiltin(b.i18n), MakeAlternatesBuiltin(b.i18n))
+	}
+	if b.http {
+		result = append(result, sxhttp.Builtins()...)
+	}
+	if b.makeGen != nil {
+		result = append(result,
+			sxhttp.MakeWriteNegotiatedBuiltin(b.makeGen),
+			sxhttp.MakeEventStreamBuiltin(b.makeGen),
+		)
+	}
+	return result
+}
+
+// Bind binds all builtins of the binder, together with the additional
+// builtins, in the given binding. Every name is prefixed. A name must occur
+// only once, so that no builtin is replaced silently.
+func (b *Binder) Bind(bind *sxeval.Binding, more ...*sxeval.Builtin) error {
+	seen := map[string]bool{}
+	for _, bi := range append(b.Builtins(), more...) {
+		name := b.prefix + bi.Name
+		if seen[name] {
+			return fmt.Errorf("builtin %q is bound twice", name)
+		}
+		seen[name] = true
+		if err := bind.Bind(sx.MakeSymbol(name), bi); err != nil {
+			return fmt.Errorf("unable to bind %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func builtinNames(builtins []*sxeval.Builtin) []string {
+	names := make([]string, len(builtins))
+	for i, b := range builtins {
+		names[i] = b.Name
+	}
+	return names
+}
+
+func TestBinderBuiltins(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	siteNames := []string{
+		"url-for", "make-url",
+		"site-current-node", "site-node-parents", "site-node-children", "site-node-title", "site-node-visible?",
+	}
+	if got := builtinNames(NewBinder(st).Builtins()); !slices.Equal(got, siteNames) {
+		t.Errorf("unexpected site builtins %v", got)
+	}
+
+	httpNames := builtinNames(sxhttp.Builtins())
+	got := builtinNames(NewBinder(st).SetHTTP().Builtins())
+	if exp := append(slices.Clone(siteNames), httpNames...); !slices.Equal(got, exp) {
+		t.Errorf("expected builtins %v, but got %v", exp, got)
+	}
+
+	got = builtinNames(NewBinder(st).SetOrigin(NewOrigin("https://example.com")).Builtins())
+	if exp := append(slices.Clone(siteNames), "absolute-url", "absolute-url-for"); !slices.Equal(got, exp) {
+		t.Errorf("expected builtins %v, but got %v", exp, got)
+	}
+
+	in := NewI18N(st, "de", "en").SetPath("intro", "de", "einfuehrung/")
+	builtins := NewBinder(st).SetI18N(in, "de").Builtins()
+	if exp := append(slices.Clone(siteNames), "current-locale", "site-alternates"); !slices.Equal(builtinNames(builtins), exp) {
+		t.Errorf("expected builtins %v, but got %v", exp, builtinNames(builtins))
+	}
+	if got, err := builtins[0].Fn1(nil, sx.MakeString("intro"), nil); err != nil || got.String() != `"/docs/de/einfuehrung/"` {
+		t.Errorf("url-for is not localised: %v/%v", got, err)
+	}
+	builtins = NewBinder(st).SetOrigin(NewOrigin("https://example.com")).SetI18N(in, "de").Builtins()
+	absURLFor := builtins[slices.IndexFunc(builtins, func(bi *sxeval.Builtin) bool { return bi.Name == "absolute-url-for" })]
+	if got, err := absURLFor.Fn(nil, sx.Vector{sx.Nil(), sx.MakeString("intro")}, nil); err != nil || got.String() != `"https://example.com/docs/de/einfuehrung/"` {
+		t.Errorf("absolute-url-for is not localised: %v/%v", got, err)
+	}
+
+	makeGen := func(*http.Request) *sxhtml.Generator { return sxhtml.NewGenerator() }
+	got = builtinNames(NewBinder(st).SetGenerator(makeGen).Builtins())
+	if exp := append(slices.Clone(siteNames), "response-write-negotiated", "sse-start"); !slices.Equal(got, exp) {
+		t.Errorf("expected builtins %v, but got %v", exp, got)
+	}
+}
+
+func TestBinderBind(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	bind := sxeval.MakeRootBinding(64)
+	binder := NewBinder(st).SetPrefix("site:").SetOrigin(NewOrigin("https://example.com")).SetHTTP()
+	if err := binder.Bind(bind, MakeURLForBuiltin(nil)); err == nil {
+		t.Error("binding url-for twice must fail")
+	}
+
+	bind = sxeval.MakeRootBinding(64)
+	extra := &sxeval.Builtin{Name: "extra"}
+	if err := binder.Bind(bind, extra); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range append(binder.Builtins(), extra) {
+		obj, found := bind.Lookup(sx.MakeSymbol("site:" + b.Name))
+		if !found {
+			t.Errorf("builtin %q not bound", b.Name)
+			continue
+		}
+		if got, isBuiltin := obj.(*sxeval.Builtin); !isBuiltin || got.Name != b.Name {
+			t.Errorf("%q: unexpected value %v", b.Name, obj)
+		}
+	}
+	if _, found := bind.Lookup(sx.MakeSymbol("url-for")); found {
+		t.Error("builtin bound without prefix")
+	}
+}
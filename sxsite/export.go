@@ -0,0 +1,275 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/webs/site"
+)
+
+// PagePrefix is the prefix of the symbol that names the Sx page procedure of
+// a node for a static export: the page of node "intro" is bound to
+// "page:intro". A page procedure (lambda (node-id) ...) returns SxHTML.
+const PagePrefix = "page:"
+
+// RenderFunc returns the SxHTML of a node. If it returns nil, no page is
+// written for the node.
+type RenderFunc func(*site.Node) (*sx.Pair, error)
+
+// ExportReport lists the results of an export.
+type ExportReport struct {
+	Written []string // file names of written pages, relative to the directory
+	Copied  []string // file names of copied assets, relative to the directory
+	Skipped []string // ids of nodes with path parameters or without a page
+}
+
+// Exporter writes all nodes of a site as static HTML files into a directory.
+//
+// The URL path of every node determines the file name: "/docs/intro/" is
+// written to "intro/index.html", if "/docs/" is the base of the site. Since
+// the files may be served from any location, even via "file://", all links
+// into the site (href and src attributes), e.g. those generated by "url-for"
+// and "make-url", are rewritten to relative links.
+type Exporter struct {
+	site   *site.Site
+	render RenderFunc
+	gen    *sxhtml.Generator
+	assets fs.FS
+}
+
+// NewExporter creates a new exporter for the site.
+func NewExporter(st *site.Site, render RenderFunc) *Exporter {
+	return &Exporter{site: st, render: render, gen: sxhtml.NewGenerator()}
+}
+
+// SetGenerator sets the generator that writes the HTML of the pages.
+func (ex *Exporter) SetGenerator(gen *sxhtml.Generator) *Exporter { ex.gen = gen; return ex }
+
+// SetAssets sets the file system of static assets, e.g. style sheets, which
+// are copied into the directory before the pages are written.
+func (ex *Exporter) SetAssets(fsys fs.FS) *Exporter { ex.assets = fsys; return ex }
+
+// Export writes all assets and pages into the given directory. Existing
+// files are overwritten, so that a site can be exported again into the same
+// directory. Nodes with path parameters cannot be exported, since their URLs
+// are unknown.
+func (ex *Exporter) Export(dir string) (*ExportReport, error) {
+	var report ExportReport
+	if ex.assets != nil {
+		copied, err := copyAssets(dir, ex.assets)
+		report.Copied = copied
+		if err != nil {
+			return &report, err
+		}
+	}
+	base := siteBase(ex.site)
+	for node := range siteNodes(ex.site) {
+		written, err := ex.exportNode(dir, base, node)
+		if err != nil {
+			return &report, fmt.Errorf("node %q: %w", node.ID(), err)
+		}
+		if written == "" {
+			report.Skipped = append(report.Skipped, node.ID())
+		} else {
+			report.Written = append(report.Written, written)
+		}
+	}
+	return &report, nil
+}
+
+// copyAssets copies all regular files of fsys into the directory, and
+// overwrites existing files. In contrast to os.CopyFS, an export may be
+// repeated.
+func copyAssets(dir string, fsys fs.FS) ([]string, error) {
+	var copied []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fullName := filepath.Join(dir, filepath.FromSlash(name))
+		if d.IsDir() {
+			return os.MkdirAll(fullName, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("asset %q is not a regular file", name)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(fullName, data, 0o644); err != nil {
+			return err
+		}
+		copied = append(copied, name)
+		return nil
+	})
+	return copied, err
+}
+
+func (ex *Exporter) exportNode(dir, base string, node *site.Node) (string, error) {
+	if hasParams(node) {
+		return "", nil
+	}
+	u, found := nodeURL(ex.site, node.ID())
+	if !found {
+		return "", nil
+	}
+	fileName, inSite := exportFileName(base, u)
+	if !inSite {
+		return "", fmt.Errorf("URL %q is not below %q", u, base)
+	}
+	page, err := ex.render(node)
+	if err != nil || page == nil {
+		return "", err
+	}
+	fromDir := path.Dir(fileName)
+	page, _ = sx.GetPair(rewriteLinks(page, func(link string) string {
+		return relativeLink(base, fromDir, link)
+	}))
+
+	var buf bytes.Buffer
+	if err = ex.gen.WriteHTML(&buf, page); err != nil {
+		return "", err
+	}
+	fullName := filepath.Join(dir, filepath.FromSlash(fileName))
+	if err = os.MkdirAll(filepath.Dir(fullName), 0o755); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(fullName, buf.Bytes(), 0o644); err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// exportFileName returns the slash-separated file name of the URL path,
+// relative to the export directory. A path that does not end with a file
+// extension denotes a directory, whose page is "index.html".
+func exportFileName(base, urlPath string) (string, bool) {
+	p, inSite := strings.CutPrefix(urlPath, base)
+	if !inSite {
+		if urlPath+"/" != base {
+			return "", false
+		}
+		p = ""
+	}
+	p = strings.TrimPrefix(p, "/")
+	if p == "" || strings.HasSuffix(p, "/") {
+		return p + "index.html", true
+	}
+	if path.Ext(p) == "" {
+		return p + "/index.html", true
+	}
+	return p, true
+}
+
+// relativeLink rewrites an absolute link into the site to a link relative
+// to the directory fromDir of the current page. Other links are returned
+// unchanged.
+func relativeLink(base, fromDir, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return link
+	}
+	target, inSite := exportFileName(base, u.Path)
+	if !inSite {
+		return link
+	}
+	u.Path = relativePath(fromDir, target)
+	return u.String()
+}
+
+// relativePath returns the slash-separated path of target, relative to the
+// directory fromDir. Both are relative to the same root.
+func relativePath(fromDir, target string) string {
+	var from []string
+	if fromDir != "." && fromDir != "" {
+		from = strings.Split(fromDir, "/")
+	}
+	to := strings.Split(target, "/")
+	i := 0
+	for i < len(from) && i < len(to)-1 && from[i] == to[i] {
+		i++
+	}
+	parts := make([]string, 0, len(from)-i+len(to)-i)
+	for range len(from) - i {
+		parts = append(parts, "..")
+	}
+	return strings.Join(append(parts, to[i:]...), "/")
+}
+
+// rewriteLinks returns a copy of the SxHTML object, where the values of all
+// href and src attributes are rewritten.
+func rewriteLinks(obj sx.Object, rewrite func(string) string) sx.Object {
+	pair, isPair := sx.GetPair(obj)
+	if !isPair || pair == nil {
+		return obj
+	}
+	if link, dotted, isLink := getLinkAttribute(pair); isLink {
+		val := sx.MakeString(rewrite(link))
+		if dotted {
+			return sx.Cons(pair.Car(), val)
+		}
+		return sx.MakeList(pair.Car(), val)
+	}
+	return sx.Cons(rewriteLinks(pair.Car(), rewrite), rewriteLinks(pair.Cdr(), rewrite))
+}
+
+// visitLinks calls visit for the values of all href and src attributes of
+// the SxHTML object, without copying it.
+func visitLinks(obj sx.Object, visit func(string)) {
+	for {
+		pair, isPair := sx.GetPair(obj)
+		if !isPair || pair == nil {
+			return
+		}
+		if link, _, isLink := getLinkAttribute(pair); isLink {
+			visit(link)
+			return
+		}
+		visitLinks(pair.Car(), visit)
+		obj = pair.Cdr()
+	}
+}
+
+// getLinkAttribute returns the value of a href or src attribute, which is
+// given as (key . "value") or as (key "value"). The second result is true
+// for the first form.
+func getLinkAttribute(pair *sx.Pair) (string, bool, bool) {
+	sym, isSymbol := sx.GetSymbol(pair.Car())
+	if !isSymbol {
+		return "", false, false
+	}
+	if key := sym.GetValue(); key != "href" && key != "src" {
+		return "", false, false
+	}
+	if s, isString := sx.GetString(pair.Cdr()); isString {
+		return s.GetValue(), true, true
+	}
+	if tail, isTail := sx.GetPair(pair.Cdr()); isTail && tail != nil && sx.IsNil(tail.Cdr()) {
+		if s, isString := sx.GetString(tail.Car()); isString {
+			return s.GetValue(), false, true
+		}
+	}
+	return "", false, false
+}
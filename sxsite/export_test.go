@@ -0,0 +1,166 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/webs/site"
+)
+
+// renderTestPage renders a page with a link to the parent node, or nil for
+// the node "users".
+func renderTestPage(st *site.Site) RenderFunc {
+	return func(node *site.Node) (*sx.Pair, error) {
+		if node.ID() == "users" {
+			return nil, nil
+		}
+		link := siteBase(st) + "style.css"
+		if parent := node.Parent(); parent != nil {
+			link, _ = nodeURL(st, parent.ID())
+		}
+		return sx.MakeList(
+			sxhtml.MakeSymbol("p"),
+			sx.MakeList(sxhtml.MakeSymbol("a"), sx.MakeList(sx.Cons(sxhtml.MakeSymbol("href"), sx.MakeString(link))), sx.MakeString(node.Title())),
+		), nil
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	dir := t.TempDir()
+	assets := fstest.MapFS{"style.css": {Data: []byte("p {}")}, "img/logo.svg": {Data: []byte("<svg/>")}}
+	for i := range 2 {
+		if i == 1 {
+			assets["style.css"] = &fstest.MapFile{Data: []byte("p { margin: 0 }")}
+		}
+		report, err := NewExporter(st, renderTestPage(st)).SetAssets(assets).Export(dir)
+		if err != nil {
+			t.Fatalf("export %d: %v", i, err)
+		}
+		if exp := []string{"index.html", "intro/index.html", "imprint/index.html"}; !slices.Equal(report.Written, exp) {
+			t.Errorf("export %d: expected written %v, but got %v", i, exp, report.Written)
+		}
+		if exp := []string{"img/logo.svg", "style.css"}; !slices.Equal(report.Copied, exp) {
+			t.Errorf("export %d: expected copied %v, but got %v", i, exp, report.Copied)
+		}
+		if exp := []string{"users", "user"}; !slices.Equal(report.Skipped, exp) {
+			t.Errorf("export %d: expected skipped %v, but got %v", i, exp, report.Skipped)
+		}
+	}
+
+	testcases := []struct {
+		fileName string
+		exp      string
+	}{
+		{"index.html", `<p><a href="style.css">Home</a></p>`},
+		{"intro/index.html", `<p><a href="../index.html">Introduction</a></p>`},
+		{"imprint/index.html", `<p><a href="../index.html">Imprint</a></p>`},
+		{"style.css", "p { margin: 0 }"},
+		{"img/logo.svg", "<svg/>"},
+	}
+	for _, tc := range testcases {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tc.fileName)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := string(data); got != tc.exp {
+			t.Errorf("%s: expected %q, but got %q", tc.fileName, tc.exp, got)
+		}
+	}
+}
+
+func TestExportFileName(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		urlPath string
+		exp     string
+		inSite  bool
+	}{
+		{"/docs/", "index.html", true},
+		{"/docs", "index.html", true},
+		{"/docs/intro/", "intro/index.html", true},
+		{"/docs/intro", "intro/index.html", true},
+		{"/docs/feed.xml", "feed.xml", true},
+		{"/other/", "", false},
+	}
+	for _, tc := range testcases {
+		got, inSite := exportFileName("/docs/", tc.urlPath)
+		if got != tc.exp || inSite != tc.inSite {
+			t.Errorf("%q: expected %q/%v, but got %q/%v", tc.urlPath, tc.exp, tc.inSite, got, inSite)
+		}
+	}
+}
+
+func TestRelativeLink(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		fromDir string
+		link    string
+		exp     string
+	}{
+		{"a/b", "/docs/c/?x=1#f", "../../c/index.html?x=1#f"},
+		{"a/b", "/docs/a/b/", "index.html"},
+		{"a/b", "/docs/a/z", "../z/index.html"},
+		{".", "/docs/style.css", "style.css"},
+		{"a", "/docs", "../index.html"},
+		{"a", "/other/x", "/other/x"},
+		{"a", "https://example.com/docs/", "https://example.com/docs/"},
+		{"a", "#top", "#top"},
+		{"a", "local.html", "local.html"},
+	}
+	for _, tc := range testcases {
+		if got := relativeLink("/docs/", tc.fromDir, tc.link); got != tc.exp {
+			t.Errorf("%q from %q: expected %q, but got %q", tc.link, tc.fromDir, tc.exp, got)
+		}
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	t.Parallel()
+	src := `(html (body (a ((href . "/x")) "href") (img ((src "/y"))) (p "href")))`
+	obj, err := sxreader.MakeReader(strings.NewReader(src)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rewriteLinks(obj, func(s string) string { return "." + s }).String()
+	exp := `(html (body (a ((href . "./x")) "href") (img ((src "./y"))) (p "href")))`
+	if got != exp {
+		t.Errorf("expected %s, but got %s", exp, got)
+	}
+}
+
+func TestVisitLinks(t *testing.T) {
+	t.Parallel()
+	src := `(html (body (a ((href . "/x")) "href") (img ((src "/y"))) (p "href" (a ((href 1))))))`
+	obj, err := sxreader.MakeReader(strings.NewReader(src)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	visitLinks(obj, func(link string) { got = append(got, link) })
+	if exp := []string{"/x", "/y"}; !slices.Equal(got, exp) {
+		t.Errorf("expected %v, but got %v", exp, got)
+	}
+}
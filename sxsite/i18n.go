@@ -0,0 +1,396 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/webs/site"
+)
+
+// I18N provides localised URLs for the nodes of a site. The URL of a node in
+// a locale starts with the locale, e.g. "/de/ueber-uns/" and "/en/about/" for
+// a node with the pattern "about/". The remaining path is either the path of
+// the node, or a translated path that was set for the node and the locale.
+//
+// Handler maps localised URLs back to the paths of the nodes, so that the
+// handlers of the nodes are found. Requests without a locale prefix are
+// served in the locale that is determined by a cookie or by the header
+// "Accept-Language".
+type I18N struct {
+	site    *site.Site
+	locales []string
+	cookie  string
+	paths   map[string]map[string]string // node id -> locale -> template
+}
+
+// NewI18N creates a new I18N for the site. The first locale is the default.
+func NewI18N(st *site.Site, locales ...string) *I18N {
+	return &I18N{site: st, locales: locales, paths: map[string]map[string]string{}}
+}
+
+// SetCookie sets the name of the cookie that stores the preferred locale.
+func (in *I18N) SetCookie(name string) *I18N { in.cookie = name; return in }
+
+// SetPath sets the translated path of a node in a locale, relative to the
+// base of the site, e.g. "benutzer/{id}/". Path parameters are written as
+// in the pattern of the node, and must occur in the same order. A trailing
+// "{$}" is ignored, since a translated path always matches exactly.
+func (in *I18N) SetPath(nodeID, locale, template string) *I18N {
+	templates := in.paths[nodeID]
+	if templates == nil {
+		templates = map[string]string{}
+		in.paths[nodeID] = templates
+	}
+	templates[locale] = strings.TrimSuffix(strings.TrimPrefix(template, "/"), "{$}")
+	return in
+}
+
+// Locales returns all locales, starting with the default.
+func (in *I18N) Locales() []string { return slices.Clone(in.locales) }
+
+type localeKey struct{}
+
+// LocaleFrom returns the locale, that was stored in the context by the
+// handler of an I18N.
+func LocaleFrom(ctx context.Context) (string, bool) {
+	locale, found := ctx.Value(localeKey{}).(string)
+	return locale, found
+}
+
+// Locale returns the locale of the request. It is taken from the URL path
+// prefix, from the cookie, from the header "Accept-Language", in that order.
+// If none of them contains a supported locale, the default is returned.
+func (in *I18N) Locale(r *http.Request) string {
+	if locale, found := LocaleFrom(r.Context()); found {
+		return locale
+	}
+	if locale, _, found := in.cutLocale(r.URL.Path); found {
+		return locale
+	}
+	return in.negotiate(r)
+}
+
+func (in *I18N) negotiate(r *http.Request) string {
+	if in.cookie != "" {
+		if c, err := r.Cookie(in.cookie); err == nil && slices.Contains(in.locales, c.Value) {
+			return c.Value
+		}
+	}
+	if locale := negotiateLocale(r.Header.Get("Accept-Language"), in.locales); locale != "" {
+		return locale
+	}
+	if len(in.locales) > 0 {
+		return in.locales[0]
+	}
+	return ""
+}
+
+// negotiateLocale returns the supported locale that is preferred by the
+// given Accept-Language header. A language range "de-AT" matches the
+// locale "de" with a lower preference.
+func negotiateLocale(accept string, locales []string) string {
+	best, bestQ := "", 0.0
+	for elem := range strings.SplitSeq(accept, ",") {
+		lang, params, _ := strings.Cut(elem, ";")
+		lang = strings.TrimSpace(lang)
+		q := 1.0
+		if key, val, found := strings.Cut(strings.TrimSpace(params), "="); found && strings.TrimSpace(key) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				q = v
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		for _, locale := range locales {
+			if strings.EqualFold(lang, locale) {
+				best, bestQ = locale, q
+				break
+			}
+			if primary, _, found := strings.Cut(lang, "-"); found && strings.EqualFold(primary, locale) && q*0.99 > bestQ {
+				best, bestQ = locale, q*0.99
+			}
+		}
+	}
+	return best
+}
+
+// cutLocale splits an URL path into the locale and the path relative to the
+// base of the site.
+func (in *I18N) cutLocale(urlPath string) (string, string, bool) {
+	rel, inSite := strings.CutPrefix(urlPath, siteBase(in.site))
+	if !inSite {
+		return "", "", false
+	}
+	locale, rest, _ := strings.Cut(rel, "/")
+	if !slices.Contains(in.locales, locale) {
+		return "", "", false
+	}
+	return locale, rest, true
+}
+
+// URL returns the URL of the node in the locale.
+func (in *I18N) URL(locale, nodeID string, vals ...string) (string, error) {
+	base := siteBase(in.site)
+	template, found := in.paths[nodeID][locale]
+	if !found {
+		u, found := nodeURL(in.site, nodeID, vals...)
+		if !found {
+			return "", fmt.Errorf("node id not found: %q", nodeID)
+		}
+		rel, inSite := strings.CutPrefix(u, base)
+		if !inSite {
+			return "", fmt.Errorf("URL %q of node %q is not below %q", u, nodeID, base)
+		}
+		return base + locale + "/" + rel, nil
+	}
+	rel, err := fillTemplate(template, vals)
+	if err != nil {
+		return "", fmt.Errorf("node %q, locale %q: %w", nodeID, locale, err)
+	}
+	return base + locale + "/" + rel, nil
+}
+
+// fillTemplate replaces all path parameters of the template by the
+// percent-encoded values. The value of "{name...}" may contain slashes,
+// which separate path segments.
+func fillTemplate(template string, vals []string) (string, error) {
+	segs := strings.Split(template, "/")
+	n := 0
+	for i, seg := range segs {
+		if !isWildcard(seg) {
+			continue
+		}
+		if n >= len(vals) {
+			return "", fmt.Errorf("missing value for %s", seg)
+		}
+		if strings.HasSuffix(seg, "...}") {
+			elems := strings.Split(vals[n], "/")
+			for j, elem := range elems {
+				elems[j] = url.PathEscape(elem)
+			}
+			segs[i] = strings.Join(elems, "/")
+		} else {
+			segs[i] = url.PathEscape(vals[n])
+		}
+		n++
+	}
+	if n != len(vals) {
+		return "", fmt.Errorf("expected %d values, but got %d", n, len(vals))
+	}
+	return strings.Join(segs, "/"), nil
+}
+
+// matchTemplate returns the values of the path parameters, if the path
+// matches the template.
+func matchTemplate(template, rel string) ([]string, bool) {
+	tsegs, psegs := strings.Split(template, "/"), strings.Split(rel, "/")
+	var vals []string
+	for i, tseg := range tsegs {
+		if isWildcard(tseg) && strings.HasSuffix(tseg, "...}") {
+			if i >= len(psegs) {
+				return nil, false
+			}
+			val, err := url.PathUnescape(strings.Join(psegs[i:], "/"))
+			return append(vals, val), err == nil
+		}
+		if i >= len(psegs) {
+			return nil, false
+		}
+		if isWildcard(tseg) {
+			val, err := url.PathUnescape(psegs[i])
+			if err != nil || val == "" {
+				return nil, false
+			}
+			vals = append(vals, val)
+		} else if tseg != psegs[i] {
+			return nil, false
+		}
+	}
+	return vals, len(tsegs) == len(psegs)
+}
+
+// Handler returns a handler that determines the locale of the request and
+// stores it in the request context. A localised URL path is replaced by the
+// path of the node, before the request is passed to next, e.g. a mux with
+// the patterns of all nodes.
+//
+// The response header "Content-Language" is set to the locale. Since the
+// locale of a path without a locale prefix depends on the header
+// "Accept-Language" and on the cookie, "Vary" lists both of them.
+func (in *I18N) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale, rel, found := in.cutLocale(r.URL.Path)
+		if !found {
+			locale = in.negotiate(r)
+		}
+		h := w.Header()
+		h.Set("Content-Language", locale)
+		h.Add("Vary", "Accept-Language, Cookie")
+		r = r.WithContext(context.WithValue(r.Context(), localeKey{}, locale))
+		if found {
+			u := *r.URL
+			u.Path, u.RawPath = in.nodePath(locale, rel), ""
+			r.URL = &u
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// nodePath returns the path of the node, whose translated path in the locale
+// matches rel. If no translated path matches, rel is the pattern of the node.
+func (in *I18N) nodePath(locale, rel string) string {
+	for _, nodeID := range slices.Sorted(maps.Keys(in.paths)) {
+		template, found := in.paths[nodeID][locale]
+		if !found {
+			continue
+		}
+		if vals, matches := matchTemplate(template, rel); matches {
+			if u, found := nodeURL(in.site, nodeID, vals...); found {
+				return u
+			}
+		}
+	}
+	return siteBase(in.site) + rel
+}
+
+// localeURLFor returns the URL of the node in the locale for the arguments
+// of an url-for call, whose node id is at the given position.
+func (in *I18N) localeURLFor(locale string, args sx.Vector, pos int) (string, error) {
+	nodeID, err := sxbuiltins.GetString(args[pos], pos)
+	if err != nil {
+		return "", err
+	}
+	ua, err := parseURLArgs(args, pos+1)
+	if err != nil {
+		return "", err
+	}
+	u, err := in.URL(locale, nodeID.GetValue(), ua.path...)
+	if err != nil {
+		return "", err
+	}
+	return ua.apply(u), nil
+}
+
+// MakeLocaleURLForBuiltin returns a builtin that provides the
+// (url-for node-id args...) function in the given locale. It accepts the
+// same arguments as the builtin of MakeURLForBuiltin, which it replaces.
+// Since the locale depends on the request, a Binder with SetI18N is
+// typically used for every request, which binds this builtin instead of the
+// other one:
+//
+//	err := sxsite.NewBinder(st).SetI18N(in, in.Locale(r)).Bind(bind)
+func MakeLocaleURLForBuiltin(in *I18N, locale string) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "url-for",
+		MinArity: 1,
+		MaxArity: -1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			return localeURLFor(in, locale, sx.Vector{arg})
+		},
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			return localeURLFor(in, locale, args)
+		},
+	}
+}
+
+func localeURLFor(in *I18N, locale string, args sx.Vector) (sx.Object, error) {
+	u, err := in.localeURLFor(locale, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sx.MakeString(u), nil
+}
+
+// MakeLocaleAbsoluteURLForBuiltin returns a builtin that provides the
+// (absolute-url-for r node-id args...) function in the given locale. It
+// replaces the builtin of MakeAbsoluteURLForBuiltin, as the builtin of
+// MakeLocaleURLForBuiltin replaces "url-for".
+func MakeLocaleAbsoluteURLForBuiltin(in *I18N, locale string, o *Origin) *sxeval.Builtin {
+	return makeAbsoluteURLForBuiltin(o, func(args sx.Vector) (string, error) {
+		return in.localeURLFor(locale, args, 1)
+	})
+}
+
+// MakeCurrentLocaleBuiltin returns a builtin that provides the
+// (current-locale r) function. It returns the locale of the request.
+func MakeCurrentLocaleBuiltin(in *I18N) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "current-locale",
+		MinArity: 1,
+		MaxArity: 1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			r, err := sxhttp.GetBuiltinRequest(arg, 0)
+			if err != nil {
+				return nil, err
+			}
+			return sx.MakeString(in.Locale(r.GetValue())), nil
+		},
+	}
+}
+
+// MakeAlternatesBuiltin returns a builtin that provides the
+// (site-alternates node-id args...) function. It returns an association list
+// of all locales and the URL of the node in this locale, followed by
+// "x-default" for the default locale, e.g. to render hreflang links:
+//
+//	(map (lambda (alt) `(link ((rel . "alternate") (hreflang . ,(car alt)) (href . ,(cdr alt)))))
+//	     (site-alternates "about"))
+func MakeAlternatesBuiltin(in *I18N) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "site-alternates",
+		MinArity: 1,
+		MaxArity: -1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			return alternates(in, sx.Vector{arg})
+		},
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			return alternates(in, args)
+		},
+	}
+}
+
+func alternates(in *I18N, args sx.Vector) (sx.Object, error) {
+	var lb sx.ListBuilder
+	defaultURL := ""
+	for _, locale := range in.locales {
+		u, err := in.localeURLFor(locale, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		lb.Add(sx.Cons(sx.MakeString(locale), sx.MakeString(u)))
+		if defaultURL == "" {
+			defaultURL = u
+		}
+	}
+	if defaultURL != "" {
+		lb.Add(sx.Cons(sx.MakeString("x-default"), sx.MakeString(defaultURL)))
+	}
+	return lb.List(), nil
+}
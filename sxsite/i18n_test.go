@@ -0,0 +1,258 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+// newTestI18N returns an I18N for the test site with the locales "de" and
+// "en", where some nodes have a german path.
+func newTestI18N(t *testing.T) *I18N {
+	t.Helper()
+	return NewI18N(newTestSite(t), "de", "en").
+		SetCookie("lang").
+		SetPath("intro", "de", "einfuehrung/").
+		SetPath("user", "de", "/benutzer/{id}/")
+}
+
+func TestI18NURL(t *testing.T) {
+	t.Parallel()
+	in := newTestI18N(t)
+	testcases := []struct {
+		locale string
+		nodeID string
+		vals   []string
+		exp    string
+	}{
+		{"de", "home", nil, "/docs/de/"},
+		{"en", "home", nil, "/docs/en/"},
+		{"de", "intro", nil, "/docs/de/einfuehrung/"},
+		{"en", "intro", nil, "/docs/en/intro/"},
+		{"de", "user", []string{"a b"}, "/docs/de/benutzer/a%20b/"},
+		{"en", "user", []string{"a b"}, "/docs/en/users/a%20b/"},
+		{"de", "user", nil, ""},
+		{"en", "user", nil, ""},
+		{"de", "none", nil, ""},
+	}
+	for _, tc := range testcases {
+		got, err := in.URL(tc.locale, tc.nodeID, tc.vals...)
+		if tc.exp == "" {
+			if err == nil {
+				t.Errorf("%s/%s %q: error expected, but got %q", tc.locale, tc.nodeID, tc.vals, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.exp {
+			t.Errorf("%s/%s %q: expected %q, but got %q/%v", tc.locale, tc.nodeID, tc.vals, tc.exp, got, err)
+		}
+	}
+	in.SetPath("imprint", "de", "impressum/{$}")
+	if got, err := in.URL("de", "imprint"); err != nil || got != "/docs/de/impressum/" {
+		t.Errorf("unexpected URL %q/%v", got, err)
+	}
+}
+
+func TestI18NNodePath(t *testing.T) {
+	t.Parallel()
+	in := newTestI18N(t)
+	testcases := []struct {
+		locale string
+		rel    string
+		exp    string
+	}{
+		{"de", "", "/docs/"},
+		{"de", "einfuehrung/", "/docs/intro/"},
+		{"de", "benutzer/a%20b/", "/docs/users/a%20b/"},
+		{"de", "intro/", "/docs/intro/"},
+		{"en", "intro/", "/docs/intro/"},
+		{"en", "einfuehrung/", "/docs/einfuehrung/"},
+		{"de", "unbekannt/", "/docs/unbekannt/"},
+	}
+	for _, tc := range testcases {
+		if got := in.nodePath(tc.locale, tc.rel); got != tc.exp {
+			t.Errorf("%s %q: expected %q, but got %q", tc.locale, tc.rel, tc.exp, got)
+		}
+	}
+}
+
+func TestI18NHandler(t *testing.T) {
+	t.Parallel()
+	in := newTestI18N(t)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale, _ := LocaleFrom(r.Context())
+		nodeID := ""
+		if node := in.site.BestNode(r); node != nil {
+			nodeID = node.ID()
+		}
+		fmt.Fprintf(w, "%s %s %s %s", locale, in.Locale(r), r.URL.Path, nodeID)
+	})
+	testcases := []struct {
+		path   string
+		header [2]string
+		exp    string
+	}{
+		{"/docs/de/einfuehrung/", [2]string{}, "de de /docs/intro/ intro"},
+		{"/docs/de/benutzer/7/", [2]string{}, "de de /docs/users/7/ user"},
+		{"/docs/en/users/7/", [2]string{}, "en en /docs/users/7/ user"},
+		{"/docs/en/", [2]string{}, "en en /docs/ home"},
+		{"/docs/intro/", [2]string{}, "de de /docs/intro/ intro"},
+		{"/docs/intro/", [2]string{"Accept-Language", "fr, en;q=0.8"}, "en en /docs/intro/ intro"},
+		{"/docs/intro/", [2]string{"Cookie", "lang=en"}, "en en /docs/intro/ intro"},
+		{"/docs/intro/", [2]string{"Cookie", "lang=fr"}, "de de /docs/intro/ intro"},
+		{"/other/", [2]string{"Accept-Language", "en"}, "en en /other/ "},
+	}
+	for _, tc := range testcases {
+		r := httptest.NewRequest("GET", tc.path, nil)
+		if tc.header[0] != "" {
+			r.Header.Set(tc.header[0], tc.header[1])
+		}
+		w := httptest.NewRecorder()
+		in.Handler(next).ServeHTTP(w, r)
+		if got := w.Body.String(); got != tc.exp {
+			t.Errorf("%s %v: expected %q, but got %q", tc.path, tc.header, tc.exp, got)
+		}
+		if got, exp := w.Header().Get("Content-Language"), strings.Fields(tc.exp)[0]; got != exp {
+			t.Errorf("%s %v: expected content language %q, but got %q", tc.path, tc.header, exp, got)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Language, Cookie" {
+			t.Errorf("%s %v: unexpected vary %q", tc.path, tc.header, got)
+		}
+	}
+}
+
+func TestI18NBuiltins(t *testing.T) {
+	t.Parallel()
+	in := newTestI18N(t)
+	request := sxhttp.MakeRequest(httptest.NewRequest("GET", "/docs/en/intro/", nil))
+	testcases := []struct {
+		name    string
+		builtin *sxeval.Builtin
+		args    sx.Vector
+		exp     string
+	}{
+		{"url-for", MakeLocaleURLForBuiltin(in, "de"), sx.Vector{sx.MakeString("intro")}, `"/docs/de/einfuehrung/"`},
+		{"url-for-args", MakeLocaleURLForBuiltin(in, "de"), sx.Vector{sx.MakeString("user"), sx.Int64(7), sx.MakeString("#top")}, `"/docs/de/benutzer/7/#top"`},
+		{"url-for-en", MakeLocaleURLForBuiltin(in, "en"), sx.Vector{sx.MakeString("user"), sx.Int64(7)}, `"/docs/en/users/7/"`},
+		{"url-for-unknown", MakeLocaleURLForBuiltin(in, "de"), sx.Vector{sx.MakeString("none")}, ""},
+		{"current-locale", MakeCurrentLocaleBuiltin(in), sx.Vector{request}, `"en"`},
+		{"current-locale-no-request", MakeCurrentLocaleBuiltin(in), sx.Vector{sx.MakeString("en")}, ""},
+		{"alternates", MakeAlternatesBuiltin(in), sx.Vector{sx.MakeString("intro")},
+			`(("de" . "/docs/de/einfuehrung/") ("en" . "/docs/en/intro/") ("x-default" . "/docs/de/einfuehrung/"))`},
+		{"alternates-args", MakeAlternatesBuiltin(in), sx.Vector{sx.MakeString("user"), sx.Int64(7)},
+			`(("de" . "/docs/de/benutzer/7/") ("en" . "/docs/en/users/7/") ("x-default" . "/docs/de/benutzer/7/"))`},
+		{"alternates-missing", MakeAlternatesBuiltin(in), sx.Vector{sx.MakeString("user")}, ""},
+		{"absolute-url-for", MakeLocaleAbsoluteURLForBuiltin(in, "de", NewOrigin("https://example.org")), sx.Vector{sx.Nil(), sx.MakeString("user"), sx.Int64(7)}, `"https://example.org/docs/de/benutzer/7/"`},
+		{"absolute-url-for-request", MakeLocaleAbsoluteURLForBuiltin(in, "en", NewOrigin("").SetAllowedHosts("example.com")), sx.Vector{request, sx.MakeString("intro")}, `"http://example.com/docs/en/intro/"`},
+		{"absolute-url-for-unknown", MakeLocaleAbsoluteURLForBuiltin(in, "de", NewOrigin("https://example.org")), sx.Vector{sx.Nil(), sx.MakeString("none")}, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got sx.Object
+			var err error
+			if len(tc.args) == 1 {
+				got, err = tc.builtin.Fn1(nil, tc.args[0], nil)
+			} else {
+				got, err = tc.builtin.Fn(nil, tc.args, nil)
+			}
+			if tc.exp == "" {
+				if err == nil {
+					t.Errorf("error expected, but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tc.exp {
+				t.Errorf("expected %s, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	t.Parallel()
+	locales := []string{"de", "en"}
+	testcases := []struct {
+		accept string
+		exp    string
+	}{
+		{"", ""},
+		{"en", "en"},
+		{"fr, en;q=0.5", "en"},
+		{"de-AT, en;q=0.9", "de"},
+		{"en;q=0.9, de-AT", "de"},
+		{"de-AT;q=0.5, en;q=0.5", "en"},
+		{"de;q=0", ""},
+	}
+	for _, tc := range testcases {
+		if got := negotiateLocale(tc.accept, locales); got != tc.exp {
+			t.Errorf("%q: expected %q, but got %q", tc.accept, tc.exp, got)
+		}
+	}
+}
+
+func TestFillTemplate(t *testing.T) {
+	t.Parallel()
+	if got, err := fillTemplate("benutzer/{id}/", []string{"a b"}); err != nil || got != "benutzer/a%20b/" {
+		t.Errorf("unexpected result %q/%v", got, err)
+	}
+	if got, err := fillTemplate("doku/{path...}", []string{"a/b"}); err != nil || got != "doku/a/b" {
+		t.Errorf("unexpected result %q/%v", got, err)
+	}
+	if got, err := fillTemplate("doku/{path...}", []string{"a b/c?d"}); err != nil || got != "doku/a%20b/c%3Fd" {
+		t.Errorf("unexpected result %q/%v", got, err)
+	}
+	if _, err := fillTemplate("benutzer/{id}/", nil); err == nil {
+		t.Error("error expected for missing value")
+	}
+	if _, err := fillTemplate("ueber-uns/", []string{"x"}); err == nil {
+		t.Error("error expected for too many values")
+	}
+}
+
+func TestMatchTemplate(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		template string
+		rel      string
+		vals     []string
+		matches  bool
+	}{
+		{"ueber-uns/", "ueber-uns/", nil, true},
+		{"ueber-uns/", "about/", nil, false},
+		{"benutzer/{id}/", "benutzer/a%20b/", []string{"a b"}, true},
+		{"benutzer/{id}/", "benutzer/x", nil, false},
+		{"benutzer/{id}/", "benutzer//", nil, false},
+		{"doku/{path...}", "doku/a/b", []string{"a/b"}, true},
+		{"doku/{path...}", "doku/a%20b/c", []string{"a b/c"}, true},
+		{"doku/{path...}", "doku", nil, false},
+	}
+	for _, tc := range testcases {
+		vals, matches := matchTemplate(tc.template, tc.rel)
+		if matches != tc.matches || (matches && !slices.Equal(vals, tc.vals)) {
+			t.Errorf("%q/%q: expected %q/%v, but got %q/%v", tc.template, tc.rel, tc.vals, tc.matches, vals, matches)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/webs/site"
+)
+
+// LinkProblem describes a broken link.
+type LinkProblem struct {
+	Source  string // the url-for form, or the id of the rendered node
+	Link    string // the node id, or the URL
+	Message string
+}
+
+func (lp LinkProblem) String() string {
+	return fmt.Sprintf("%s: %s: %s", lp.Source, lp.Link, lp.Message)
+}
+
+// LinkChecker checks links into a site, either statically by analysing Sx
+// source code, or dynamically by rendering all pages.
+type LinkChecker struct {
+	site    *site.Site
+	render  RenderFunc
+	assets  fs.FS
+	mux     *http.ServeMux // patterns of all nodes, that match only exactly
+	urlFor  *sx.Symbol
+	makeURL *sx.Symbol
+}
+
+// NewLinkChecker creates a new link checker for the site.
+func NewLinkChecker(st *site.Site) *LinkChecker {
+	lc := &LinkChecker{site: st, mux: http.NewServeMux()}
+	lc.SetPrefix("")
+	for node := range siteNodes(st) {
+		pattern := nodePattern(st, node)
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "{$}"
+		}
+		// The patterns of the site are valid. An error only occurs, if an
+		// equivalent pattern is already registered.
+		_ = sxhttp.HandleMux(lc.mux, pattern, http.NotFoundHandler())
+	}
+	return lc
+}
+
+// SetPrefix sets the prefix of the names "url-for" and "make-url", which
+// CheckSource looks for. It must be the prefix of Binder.SetPrefix.
+func (lc *LinkChecker) SetPrefix(prefix string) *LinkChecker {
+	lc.urlFor = sx.MakeSymbol(prefix + "url-for")
+	lc.makeURL = sx.MakeSymbol(prefix + "make-url")
+	return lc
+}
+
+// SetRender sets the function to render the pages of the site for Crawl.
+func (lc *LinkChecker) SetRender(render RenderFunc) *LinkChecker { lc.render = render; return lc }
+
+// SetAssets sets the file system of static files, which are served below
+// the base path of the site. Links to these files are valid too.
+func (lc *LinkChecker) SetAssets(fsys fs.FS) *LinkChecker { lc.assets = fsys; return lc }
+
+// CheckSource scans the Sx source code for calls of "url-for" with a literal
+// node id, and for calls of "make-url". It reports node ids that are not
+// found, calls of "url-for" whose number of path values does not match the
+// number of path parameters of the node, and calls of "make-url" that refer
+// neither to a node nor to an asset. If the builtins are bound with a
+// prefix, SetPrefix must be called with the same prefix.
+//
+// Path values are only checked, if they are known statically, i.e. if no
+// argument is a variable or computed by a function call.
+func (lc *LinkChecker) CheckSource(objs ...sx.Object) []LinkProblem {
+	var problems []LinkProblem
+	for _, obj := range objs {
+		problems = lc.checkForm(obj, problems)
+	}
+	return problems
+}
+
+var symQuote = sx.MakeSymbol("quote")
+
+func (lc *LinkChecker) checkForm(obj sx.Object, problems []LinkProblem) []LinkProblem {
+	lst, isPair := sx.GetPair(obj)
+	if !isPair || lst == nil {
+		return problems
+	}
+	switch {
+	case lst.Car().IsEqual(symQuote):
+		return problems
+	case lst.Car().IsEqual(lc.urlFor):
+		problems = lc.checkURLFor(lst, problems)
+	case lst.Car().IsEqual(lc.makeURL):
+		problems = lc.checkMakeURL(lst, problems)
+	}
+	for ; lst != nil; lst = lst.Tail() {
+		problems = lc.checkForm(lst.Car(), problems)
+		if _, isList := sx.GetPair(lst.Cdr()); !isList {
+			break
+		}
+	}
+	return problems
+}
+
+func (lc *LinkChecker) checkURLFor(form *sx.Pair, problems []LinkProblem) []LinkProblem {
+	args := form.Tail()
+	if args == nil {
+		return problems
+	}
+	nodeID, isString := sx.GetString(args.Car())
+	if !isString {
+		return problems
+	}
+	node := lc.site.GetNode(nodeID.GetValue())
+	if node == nil {
+		return append(problems, LinkProblem{Source: form.String(), Link: nodeID.GetValue(), Message: "node id not found"})
+	}
+	vals, known := literalPathValues(args.Tail())
+	if need := numParams(node); known && len(vals) != need {
+		return append(problems, LinkProblem{
+			Source:  form.String(),
+			Link:    nodeID.GetValue(),
+			Message: fmt.Sprintf("node needs %d path values, but got %d", need, len(vals)),
+		})
+	}
+	return problems
+}
+
+func (lc *LinkChecker) checkMakeURL(form *sx.Pair, problems []LinkProblem) []LinkProblem {
+	vals, known := literalPathValues(form.Tail())
+	if !known {
+		return problems
+	}
+	link := pathURL(lc.site, vals...)
+	u, err := url.Parse(link)
+	if err != nil {
+		return append(problems, LinkProblem{Source: form.String(), Link: link, Message: err.Error()})
+	}
+	if msg := lc.checkPath(u.Path); msg != "" {
+		return append(problems, LinkProblem{Source: form.String(), Link: link, Message: msg})
+	}
+	return problems
+}
+
+// literalPathValues returns the path values of the arguments of an URL
+// builtin, as parsed by parseURLArgs. If they are not known statically,
+// because an argument is a variable or a function call, false is returned.
+func literalPathValues(args *sx.Pair) ([]string, bool) {
+	var vals []string
+	for node := range args.Pairs() {
+		if _, isFragment := getFragment(node.Car()); isFragment && node.Tail() == nil {
+			return vals, true
+		}
+		obj := node.Car()
+		switch o := obj.(type) {
+		case *sx.Symbol:
+			if len(o.GetValue()) > 1 && strings.HasPrefix(o.GetValue(), ":") {
+				return vals, true
+			}
+			return vals, false
+		case *sx.Pair:
+			if o == nil || !o.Car().IsEqual(symQuote) {
+				return vals, false
+			}
+			quoted := o.Tail().Car()
+			if _, isPair := sx.GetPair(quoted); isPair {
+				return vals, true
+			}
+			obj = quoted
+		}
+		val, err := getURLValue(obj, 0)
+		if err != nil {
+			return vals, false
+		}
+		vals = append(vals, val)
+	}
+	return vals, true
+}
+
+// Crawl renders all pages without path parameters and checks the values of
+// all their href and src attributes that refer into the site. A link is
+// valid, if it matches the pattern of a node exactly, or if it refers to a
+// static asset.
+func (lc *LinkChecker) Crawl() ([]LinkProblem, error) {
+	if lc.render == nil {
+		return nil, fmt.Errorf("no render function for site %q", siteBase(lc.site))
+	}
+	var problems []LinkProblem
+	for node := range siteNodes(lc.site) {
+		if hasParams(node) {
+			continue
+		}
+		u, found := nodeURL(lc.site, node.ID())
+		if !found {
+			continue
+		}
+		page, err := lc.render(node)
+		if err != nil {
+			return problems, fmt.Errorf("node %q: %w", node.ID(), err)
+		}
+		pageURL, err := url.Parse(u)
+		if err != nil {
+			return problems, fmt.Errorf("node %q: %w", node.ID(), err)
+		}
+		visitLinks(page, func(link string) {
+			if msg := lc.checkLink(pageURL, link); msg != "" {
+				problems = append(problems, LinkProblem{Source: node.ID(), Link: link, Message: msg})
+			}
+		})
+	}
+	return problems, nil
+}
+
+// checkLink returns a message, if the link of the page is not valid.
+func (lc *LinkChecker) checkLink(pageURL *url.URL, link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return err.Error()
+	}
+	if u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+	return lc.checkPath(pageURL.ResolveReference(u).Path)
+}
+
+// checkPath returns a message, if the absolute URL path refers into the
+// site, but neither to a node nor to an asset.
+func (lc *LinkChecker) checkPath(urlPath string) string {
+	base := siteBase(lc.site)
+	if !strings.HasPrefix(urlPath, base) && urlPath+"/" != base {
+		return ""
+	}
+	if r, err := http.NewRequest(http.MethodGet, urlPath, nil); err == nil {
+		if _, pattern := lc.mux.Handler(r); pattern != "" {
+			return ""
+		}
+	}
+	if lc.assets != nil {
+		if name := strings.TrimPrefix(urlPath, base); fs.ValidPath(name) {
+			if _, err := fs.Stat(lc.assets, name); err == nil {
+				return ""
+			}
+		}
+	}
+	return "not found"
+}
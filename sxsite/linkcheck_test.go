@@ -0,0 +1,220 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxreader"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/webs/site"
+)
+
+// readSx reads the first Sx object of the source.
+func readSx(t *testing.T, src string) sx.Object {
+	t.Helper()
+	obj, err := sxreader.MakeReader(strings.NewReader(src)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestLiteralPathValues(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		src   string
+		vals  []string
+		known bool
+	}{
+		{`()`, nil, true},
+		{`("a" 42 'b)`, []string{"a", "42", "b"}, true},
+		{`("a" 42 b)`, []string{"a", "42"}, false},
+		{`(42 '((tab . "posts")))`, []string{"42"}, true},
+		{`(17 :tab "posts")`, []string{"17"}, true},
+		{`("user" "#top")`, []string{"user"}, true},
+		{`("#tag" "x")`, []string{"#tag", "x"}, true},
+		{`("#tag" "#")`, []string{"#tag"}, true},
+		{`((user-id u))`, nil, false},
+		{`("a" (make-query))`, []string{"a"}, false},
+	}
+	for _, tc := range testcases {
+		args, _ := sx.GetPair(readSx(t, tc.src))
+		vals, known := literalPathValues(args)
+		if !slices.Equal(vals, tc.vals) || known != tc.known {
+			t.Errorf("%s: expected %q/%v, but got %q/%v", tc.src, tc.vals, tc.known, vals, known)
+		}
+	}
+}
+
+func TestCheckSource(t *testing.T) {
+	t.Parallel()
+	lc := NewLinkChecker(newTestSite(t)).SetAssets(fstest.MapFS{"style.css": {}})
+	testcases := []struct {
+		src string
+		exp string
+	}{
+		{`(url-for "intro")`, ""},
+		{`(url-for "user" 7 "#top")`, ""},
+		{`(url-for "user" id)`, ""},
+		{`(url-for node-id)`, ""},
+		{`(url-for "none")`, "none: node id not found"},
+		{`(url-for "user")`, "user: node needs 1 path values, but got 0"},
+		{`(url-for "intro" 1 2)`, "intro: node needs 0 path values, but got 2"},
+		{`(make-url)`, ""},
+		{`(make-url "style.css")`, ""},
+		{`(make-url "users" 7 :tab "posts")`, ""},
+		{`(make-url "intro" "#top")`, ""},
+		{`(make-url file)`, ""},
+		{`(make-url "script.js")`, "/docs/script.js: not found"},
+		{`(make-url "users" 7 "x")`, "/docs/users/7/x: not found"},
+		{`'(url-for "none")`, ""},
+		{`(list 'a (list (cons 'href (url-for "none"))))`, "none: node id not found"},
+	}
+	for _, tc := range testcases {
+		var got []string
+		for _, problem := range lc.CheckSource(readSx(t, tc.src)) {
+			got = append(got, problem.Link+": "+problem.Message)
+		}
+		if exp := strings.Join(got, "; "); exp != tc.exp {
+			t.Errorf("%s: expected %q, but got %q", tc.src, tc.exp, exp)
+		}
+	}
+}
+
+func TestCheckSourcePrefix(t *testing.T) {
+	t.Parallel()
+	lc := NewLinkChecker(newTestSite(t)).SetPrefix("site:")
+	src := readSx(t, `(list (site:url-for "none") (site:make-url "missing") (url-for "other"))`)
+	var got []string
+	for _, problem := range lc.CheckSource(src) {
+		got = append(got, problem.Link)
+	}
+	if exp := []string{"none", "/docs/missing"}; !slices.Equal(got, exp) {
+		t.Errorf("expected problems %q, but got %q", exp, got)
+	}
+}
+
+func TestCheckSourceWildcards(t *testing.T) {
+	t.Parallel()
+	root := site.NewNode("home", "Home", "")
+	root.AddChild(site.NewNode("news", "News", "news/{$}"))
+	root.AddChild(site.NewNode("files", "Files", "files/{path...}"))
+	st, err := site.New("Wildcards", "/", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc := NewLinkChecker(st)
+	testcases := []struct {
+		src string
+		exp int
+	}{
+		{`(url-for "news")`, 0},
+		{`(url-for "news" 1)`, 1},
+		{`(url-for "files" "a/b")`, 0},
+		{`(url-for "files")`, 1},
+	}
+	for _, tc := range testcases {
+		if got := lc.CheckSource(readSx(t, tc.src)); len(got) != tc.exp {
+			t.Errorf("%s: expected %d problems, but got %v", tc.src, tc.exp, got)
+		}
+	}
+}
+
+func TestCheckLink(t *testing.T) {
+	t.Parallel()
+	lc := NewLinkChecker(newTestSite(t)).SetAssets(fstest.MapFS{"img/logo.svg": {}})
+	pageURL, err := url.Parse("/docs/intro/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases := []struct {
+		link string
+		exp  string
+	}{
+		{"/docs/", ""},
+		{"/docs", ""},
+		{"../", ""},
+		{"./", ""},
+		{"/docs/users/7/", ""},
+		{"../users/7/?tab=posts#top", ""},
+		{"/docs/imprint/", ""},
+		{"/docs/img/logo.svg", ""},
+		{"../img/logo.svg", ""},
+		{"#top", ""},
+		{"https://example.com/docs/missing/", ""},
+		{"/other/", ""},
+		{"/docs/missing/", "not found"},
+		{"missing", "not found"},
+		{"/docs/users/7/x", "not found"},
+		{"/docs/img/", "not found"},
+	}
+	for _, tc := range testcases {
+		if got := lc.checkLink(pageURL, tc.link); got != tc.exp {
+			t.Errorf("%q: expected %q, but got %q", tc.link, tc.exp, got)
+		}
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	render := func(node *site.Node) (*sx.Pair, error) {
+		var links []sx.Object
+		switch node.ID() {
+		case "home":
+			links = []sx.Object{sx.MakeString("intro/"), sx.MakeString("missing/")}
+		case "intro":
+			links = []sx.Object{sx.MakeString("../users/7/"), sx.MakeString("../style.css")}
+		case "imprint":
+			return nil, nil
+		}
+		var lb sx.ListBuilder
+		lb.Add(sxhtml.MakeSymbol("p"))
+		for _, link := range links {
+			lb.Add(sx.MakeList(sxhtml.MakeSymbol("a"), sx.MakeList(sx.Cons(sxhtml.MakeSymbol("href"), link))))
+		}
+		return lb.List(), nil
+	}
+	if _, err := NewLinkChecker(st).Crawl(); err == nil {
+		t.Error("crawl without render function must fail")
+	}
+	problems, err := NewLinkChecker(st).SetRender(render).Crawl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	if exp := []string{"home: missing/: not found", "intro: ../style.css: not found"}; !slices.Equal(got, exp) {
+		t.Errorf("expected problems %q, but got %q", exp, got)
+	}
+
+	problems, err = NewLinkChecker(st).SetRender(render).SetAssets(fstest.MapFS{"style.css": {}}).Crawl()
+	if err != nil || len(problems) != 1 {
+		t.Errorf("expected one problem, but got %v/%v", problems, err)
+	}
+
+	failing := func(*site.Node) (*sx.Pair, error) { return nil, fmt.Errorf("render failed") }
+	if _, err = NewLinkChecker(st).SetRender(failing).Crawl(); err == nil {
+		t.Error("error of render function must be returned")
+	}
+}
@@ -0,0 +1,161 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"slices"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxbuiltins"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/webs/site"
+)
+
+// Navigation builtins refer to nodes of a webs/site.Site by their node id,
+// as "url-for" does. This allows to combine them, e.g. to render a menu:
+//
+//	(map (lambda (id) `(li (a ((href . ,(url-for id))) ,(site-node-title id))))
+//	     (site-node-children (site-current-node r)))
+
+// getBuiltinNode returns the node of the site with the node id given as a
+// string.
+func getBuiltinNode(st *site.Site, arg sx.Object, pos int) (*site.Node, error) {
+	nodeID, err := sxbuiltins.GetString(arg, pos)
+	if err != nil {
+		return nil, err
+	}
+	node := st.GetNode(nodeID.GetValue())
+	if node == nil {
+		return nil, fmt.Errorf("node id not found: %v", nodeID)
+	}
+	return node, nil
+}
+
+// MakeCurrentNodeBuiltin returns a builtin that provides the
+// (site-current-node r) function. It returns the id of the node that best
+// matches the request, or nil.
+func MakeCurrentNodeBuiltin(st *site.Site) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "site-current-node",
+		MinArity: 1,
+		MaxArity: 1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			r, err := sxhttp.GetBuiltinRequest(arg, 0)
+			if err != nil {
+				return nil, err
+			}
+			if node := st.BestNode(r.GetValue()); node != nil {
+				return sx.MakeString(node.ID()), nil
+			}
+			return sx.Nil(), nil
+		},
+	}
+}
+
+// MakeNodeParentsBuiltin returns a builtin that provides the
+// (site-node-parents node-id) function. It returns the ids of all parent
+// nodes, starting with the root node, e.g. to render a breadcrumb.
+func MakeNodeParentsBuiltin(st *site.Site) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "site-node-parents",
+		MinArity: 1,
+		MaxArity: 1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			node, err := getBuiltinNode(st, arg, 0)
+			if err != nil {
+				return nil, err
+			}
+			var ids []sx.Object
+			for p := node.Parent(); p != nil; p = p.Parent() {
+				ids = append(ids, sx.MakeString(p.ID()))
+			}
+			slices.Reverse(ids)
+			return sx.MakeList(ids...), nil
+		},
+	}
+}
+
+// MakeNodeChildrenBuiltin returns a builtin that provides the
+// (site-node-children node-id all) function. It returns the ids of the
+// visible child nodes. If the optional argument "all" is true, invisible
+// child nodes are returned too.
+func MakeNodeChildrenBuiltin(st *site.Site) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "site-node-children",
+		MinArity: 1,
+		MaxArity: 2,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			return nodeChildren(st, arg, false)
+		},
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			return nodeChildren(st, args[0], len(args) > 1 && args[1].IsTrue())
+		},
+	}
+}
+
+func nodeChildren(st *site.Site, arg sx.Object, all bool) (sx.Object, error) {
+	node, err := getBuiltinNode(st, arg, 0)
+	if err != nil {
+		return nil, err
+	}
+	var lb sx.ListBuilder
+	for _, child := range node.Children() {
+		if all || child.IsVisible() {
+			lb.Add(sx.MakeString(child.ID()))
+		}
+	}
+	return lb.List(), nil
+}
+
+// MakeNodeTitleBuiltin returns a builtin that provides the
+// (site-node-title node-id) function.
+func MakeNodeTitleBuiltin(st *site.Site) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "site-node-title",
+		MinArity: 1,
+		MaxArity: 1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			node, err := getBuiltinNode(st, arg, 0)
+			if err != nil {
+				return nil, err
+			}
+			return sx.MakeString(node.Title()), nil
+		},
+	}
+}
+
+// MakeNodeVisibleBuiltin returns a builtin that provides the
+// (site-node-visible? node-id) function. Invisible nodes are not shown in
+// menus, but can be reached by their URL.
+func MakeNodeVisibleBuiltin(st *site.Site) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     "site-node-visible?",
+		MinArity: 1,
+		MaxArity: 1,
+		TestPure: sxeval.AssertPure,
+		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
+			node, err := getBuiltinNode(st, arg, 0)
+			if err != nil {
+				return nil, err
+			}
+			return sx.MakeBoolean(node.IsVisible()), nil
+		},
+	}
+}
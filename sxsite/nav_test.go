@@ -0,0 +1,74 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+func TestNavBuiltins(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	request := func(path string) sx.Object { return sxhttp.MakeRequest(httptest.NewRequest("GET", path, nil)) }
+	testcases := []struct {
+		name    string
+		builtin *sxeval.Builtin
+		args    sx.Vector
+		exp     string
+	}{
+		{"current", MakeCurrentNodeBuiltin(st), sx.Vector{request("/docs/users/7/")}, `"user"`},
+		{"current-none", MakeCurrentNodeBuiltin(st), sx.Vector{request("/other/")}, "()"},
+		{"parents", MakeNodeParentsBuiltin(st), sx.Vector{sx.MakeString("user")}, `("home" "users")`},
+		{"parents-root", MakeNodeParentsBuiltin(st), sx.Vector{sx.MakeString("home")}, "()"},
+		{"children", MakeNodeChildrenBuiltin(st), sx.Vector{sx.MakeString("home")}, `("intro" "users")`},
+		{"children-all", MakeNodeChildrenBuiltin(st), sx.Vector{sx.MakeString("home"), sx.MakeSymbol("all")}, `("intro" "users" "imprint")`},
+		{"children-visible", MakeNodeChildrenBuiltin(st), sx.Vector{sx.MakeString("home"), sx.Nil()}, `("intro" "users")`},
+		{"children-leaf", MakeNodeChildrenBuiltin(st), sx.Vector{sx.MakeString("intro")}, "()"},
+		{"title", MakeNodeTitleBuiltin(st), sx.Vector{sx.MakeString("intro")}, `"Introduction"`},
+		{"visible", MakeNodeVisibleBuiltin(st), sx.Vector{sx.MakeString("intro")}, sx.MakeBoolean(true).String()},
+		{"hidden", MakeNodeVisibleBuiltin(st), sx.Vector{sx.MakeString("imprint")}, sx.MakeBoolean(false).String()},
+		{"url-for", MakeURLForBuiltin(st), sx.Vector{sx.MakeString("user"), sx.Int64(7), sx.MakeString("#top")}, `"/docs/users/7/#top"`},
+		{"make-url", MakeMakeURLBuiltin(st), sx.Vector{sx.MakeString("style.css")}, `"/docs/style.css"`},
+		{"unknown", MakeNodeTitleBuiltin(st), sx.Vector{sx.MakeString("none")}, ""},
+		{"no-string", MakeNodeParentsBuiltin(st), sx.Vector{sx.Int64(1)}, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got sx.Object
+			var err error
+			if len(tc.args) == 1 {
+				got, err = tc.builtin.Fn1(nil, tc.args[0], nil)
+			} else {
+				got, err = tc.builtin.Fn(nil, tc.args, nil)
+			}
+			if tc.exp == "" {
+				if err == nil {
+					t.Errorf("error expected, but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tc.exp {
+				t.Errorf("expected %s, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
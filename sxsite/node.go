@@ -0,0 +1,103 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"iter"
+	"strings"
+
+	"t73f.de/r/webs/site"
+)
+
+// siteNodes returns all nodes of the site, parents before their children.
+func siteNodes(st *site.Site) iter.Seq[*site.Node] {
+	return func(yield func(*site.Node) bool) {
+		var walk func(*site.Node) bool
+		walk = func(node *site.Node) bool {
+			if !yield(node) {
+				return false
+			}
+			for _, child := range node.Children() {
+				if !walk(child) {
+					return false
+				}
+			}
+			return true
+		}
+		if root := st.Root(); root != nil {
+			walk(root)
+		}
+	}
+}
+
+// siteBase returns the base path of the site, which ends with a slash.
+func siteBase(st *site.Site) string {
+	base := st.MakeURLBuilder().String()
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base
+}
+
+// nodeURL returns the URL of the node with the given path values, or false,
+// if there is no such node.
+func nodeURL(st *site.Site, nodeID string, vals ...string) (string, bool) {
+	args := make([]any, len(vals))
+	for i, val := range vals {
+		args[i] = val
+	}
+	ub := st.BuilderFor(nodeID, args...)
+	if ub == nil {
+		return "", false
+	}
+	return ub.String(), true
+}
+
+// pathURL returns the URL of the path elements, relative to the base of the
+// site.
+func pathURL(st *site.Site, elems ...string) string {
+	ub := st.MakeURLBuilder()
+	for _, elem := range elems {
+		ub = ub.AddPath(elem)
+	}
+	return ub.String()
+}
+
+// nodePattern returns the pattern of the node for a http.ServeMux, i.e. its
+// pattern below the base path of the site.
+func nodePattern(st *site.Site, node *site.Node) string { return siteBase(st) + node.Pattern() }
+
+// nodeMeta returns the metadata value of the node for the given key.
+func nodeMeta(node *site.Node, key string) (string, bool) { return node.Meta(key) }
+
+// numParams returns the number of wildcards of the node pattern, whose values
+// are needed to build its URL.
+func numParams(node *site.Node) int {
+	n := 0
+	for seg := range strings.SplitSeq(node.Pattern(), "/") {
+		if isWildcard(seg) {
+			n++
+		}
+	}
+	return n
+}
+
+// hasParams returns true, if the URL of the node needs path values.
+func hasParams(node *site.Node) bool { return numParams(node) > 0 }
+
+// isWildcard returns true, if the pattern segment is a wildcard "{name}" or
+// "{name...}". The segment "{$}" only marks the end of a pattern.
+func isWildcard(seg string) bool {
+	return len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' && seg != "{$}"
+}
@@ -0,0 +1,118 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"testing"
+
+	"t73f.de/r/webs/site"
+)
+
+// newTestSite returns a small site, with the base path "/docs/".
+func newTestSite(t *testing.T) *site.Site {
+	t.Helper()
+	users := site.NewNode("users", "Users", "users/")
+	users.AddChild(site.NewNode("user", "User", "users/{id}/"))
+	imprint := site.NewNode("imprint", "Imprint", "imprint/")
+	imprint.SetInvisible()
+	root := site.NewNode("home", "Home", "")
+	root.AddChild(site.NewNode("intro", "Introduction", "intro/"))
+	root.AddChild(users)
+	root.AddChild(imprint)
+	st, err := site.New("Documentation", "/docs/", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestSiteNodes(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	var ids []string
+	for node := range siteNodes(st) {
+		ids = append(ids, node.ID())
+	}
+	if got := fmt.Sprint(ids); got != "[home intro users user imprint]" {
+		t.Errorf("unexpected nodes %s", got)
+	}
+	for node := range siteNodes(st) {
+		if node.ID() != "home" {
+			t.Errorf("iteration not stopped at %q", node.ID())
+		}
+		break
+	}
+	if got := siteBase(st); got != "/docs/" {
+		t.Errorf("unexpected base %q", got)
+	}
+	if got := nodePattern(st, st.GetNode("user")); got != "/docs/users/{id}/" {
+		t.Errorf("unexpected pattern %q", got)
+	}
+	for id, exp := range map[string]bool{"home": false, "users": false, "user": true} {
+		if got := hasParams(st.GetNode(id)); got != exp {
+			t.Errorf("%s: expected params %v, but got %v", id, exp, got)
+		}
+	}
+}
+
+func TestIsWildcard(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		seg string
+		exp bool
+	}{
+		{"{id}", true},
+		{"{path...}", true},
+		{"{$}", false},
+		{"{}", false},
+		{"users", false},
+		{"", false},
+	}
+	for _, tc := range testcases {
+		if got := isWildcard(tc.seg); got != tc.exp {
+			t.Errorf("%q: expected %v, but got %v", tc.seg, tc.exp, got)
+		}
+	}
+}
+
+func TestNodeURL(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	testcases := []struct {
+		nodeID string
+		vals   []string
+		exp    string
+	}{
+		{"home", nil, "/docs/"},
+		{"intro", nil, "/docs/intro/"},
+		{"user", []string{"a b"}, "/docs/users/a%20b/"},
+		{"none", nil, ""},
+	}
+	for _, tc := range testcases {
+		got, found := nodeURL(st, tc.nodeID, tc.vals...)
+		if tc.exp == "" {
+			if found {
+				t.Errorf("%s %q: no URL expected, but got %q", tc.nodeID, tc.vals, got)
+			}
+			continue
+		}
+		if !found || got != tc.exp {
+			t.Errorf("%s %q: expected %q, but got %q/%v", tc.nodeID, tc.vals, tc.exp, got, found)
+		}
+	}
+	if got := pathURL(st, "a", "b c"); got != "/docs/a/b%20c" {
+		t.Errorf("unexpected path URL %q", got)
+	}
+}
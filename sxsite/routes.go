@@ -0,0 +1,91 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+	"t73f.de/r/webs/site"
+)
+
+// HandlerPrefix is the prefix of the symbol that names the Sx handler
+// procedure of a node: the handler of node "user" is bound to "handler:user".
+const HandlerPrefix = "handler:"
+
+// RouteReport lists the results of RegisterNodes.
+type RouteReport struct {
+	Registered     []string // ids of nodes, whose handler was registered
+	MissingHandler []string // ids of nodes without a handler
+	MissingNode    []string // names of handler symbols without a node
+}
+
+// RegisterNodes walks all nodes of the site and registers the Sx handler
+// procedure (lambda (w r) ...) of every node on the mux, using the pattern of
+// the node below the base path of the site. Handlers are evaluated as
+// configured by rt, e.g. with its budget.
+//
+// Only symbols that are bound directly in bind are handlers, not those of
+// its parent bindings. The report lists nodes without a handler and handlers
+// without a node, so that an application may decide whether this is an
+// error. All handlers and patterns are validated before the first one is
+// registered, so that an invalid handler or a conflicting pattern leaves the
+// mux unchanged, as with sxhttp.Routes.Register.
+func RegisterNodes(mux *http.ServeMux, rt *sxhttp.Routes, st *site.Site, bind *sxeval.Binding) (*RouteReport, error) {
+	handlers := map[string]*sx.Symbol{}
+	for _, sym := range bind.Symbols() {
+		if nodeID, isHandler := strings.CutPrefix(sym.GetValue(), HandlerPrefix); isHandler {
+			handlers[nodeID] = sym
+		}
+	}
+
+	var report RouteReport
+	var routes []sxhttp.Route
+	var nodeIDs []string
+	for node := range siteNodes(st) {
+		nodeID := node.ID()
+		sym, found := handlers[nodeID]
+		if !found {
+			report.MissingHandler = append(report.MissingHandler, nodeID)
+			continue
+		}
+		delete(handlers, nodeID)
+		obj, _ := bind.Lookup(sym)
+		proc, isCallable := sxeval.GetCallable(obj)
+		if !isCallable {
+			return &report, fmt.Errorf("handler of node %q is not a procedure, but %T/%v", nodeID, obj, obj)
+		}
+		routes = append(routes, sxhttp.Route{Pattern: nodePattern(st, node), Proc: proc})
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	if err := rt.HandleAll(mux, routes); err != nil {
+		if re, isRoute := errors.AsType[*sxhttp.RouteError](err); isRoute {
+			return &report, fmt.Errorf("node %q: %w", nodeIDs[re.Pos-1], re.Err)
+		}
+		return &report, err
+	}
+	report.Registered = nodeIDs
+
+	for _, sym := range handlers {
+		report.MissingNode = append(report.MissingNode, sym.GetValue())
+	}
+	slices.Sort(report.MissingNode)
+	return &report, nil
+}
@@ -0,0 +1,148 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sxwebs/sxhttp"
+)
+
+// makeNodeHandler returns a handler procedure (lambda (w r) ...), that writes
+// its name and the path value "id".
+func makeNodeHandler(name string) *sxeval.Builtin {
+	return &sxeval.Builtin{
+		Name:     name,
+		MinArity: 2,
+		MaxArity: 2,
+		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
+			w, err := sxhttp.GetBuiltinResponseWriter(args[0], 0)
+			if err != nil {
+				return nil, err
+			}
+			r, err := sxhttp.GetBuiltinRequest(args[1], 1)
+			if err != nil {
+				return nil, err
+			}
+			_, err = io.WriteString(w.GetValue(), name+r.GetValue().PathValue("id"))
+			return sx.Nil(), err
+		},
+	}
+}
+
+func TestRegisterNodes(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	bind := sxeval.MakeRootBinding(16)
+	for _, name := range []string{"home", "user", "unknown"} {
+		if err := bind.Bind(sx.MakeSymbol(HandlerPrefix+name), makeNodeHandler(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	makeEnv := func(*http.Request) (*sxeval.Environment, error) {
+		return sxeval.MakeEnvironment(sxeval.MakeRootBinding(16)), nil
+	}
+	mux := http.NewServeMux()
+	report, err := RegisterNodes(mux, sxhttp.NewRoutes(makeEnv, sxhttp.BindingResolver(bind)), st, bind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"home", "user"}; !slices.Equal(report.Registered, exp) {
+		t.Errorf("expected registered %v, but got %v", exp, report.Registered)
+	}
+	if exp := []string{"intro", "users", "imprint"}; !slices.Equal(report.MissingHandler, exp) {
+		t.Errorf("expected missing handlers %v, but got %v", exp, report.MissingHandler)
+	}
+	if exp := []string{HandlerPrefix + "unknown"}; !slices.Equal(report.MissingNode, exp) {
+		t.Errorf("expected missing nodes %v, but got %v", exp, report.MissingNode)
+	}
+
+	testcases := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/docs/", http.StatusOK, "home"},
+		{"/docs/users/7/", http.StatusOK, "user7"},
+		{"/docs/intro/", http.StatusOK, "home"}, // "/docs/" matches all paths below
+		{"/intro/", http.StatusNotFound, ""},
+	}
+	for _, tc := range testcases {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, but got %d", tc.path, tc.status, w.Code)
+			continue
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Errorf("%s: expected %q, but got %q", tc.path, tc.body, w.Body.String())
+		}
+	}
+}
+
+func TestRegisterNodesError(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name    string
+		handler sx.Object
+		mux     func() *http.ServeMux
+	}{
+		{"no-procedure", sx.MakeString("home"), http.NewServeMux},
+		{"conflict", makeNodeHandler("home"), func() *http.ServeMux {
+			mux := http.NewServeMux()
+			mux.Handle("/docs/", http.NotFoundHandler())
+			return mux
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			bind := sxeval.MakeRootBinding(16)
+			if err := bind.Bind(sx.MakeSymbol(HandlerPrefix+"home"), tc.handler); err != nil {
+				t.Fatal(err)
+			}
+			rt := sxhttp.NewRoutes(nil, sxhttp.BindingResolver(bind))
+			report, err := RegisterNodes(tc.mux(), rt, newTestSite(t), bind)
+			if err == nil {
+				t.Errorf("error expected, but got %+v", report)
+			}
+		})
+	}
+}
+
+func TestRegisterNodesConflict(t *testing.T) {
+	t.Parallel()
+	bind := sxeval.MakeRootBinding(16)
+	for _, name := range []string{"home", "user"} {
+		if err := bind.Bind(sx.MakeSymbol(HandlerPrefix+name), makeNodeHandler(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rt := sxhttp.NewRoutes(nil, sxhttp.BindingResolver(bind))
+	mux := http.NewServeMux()
+	if err := rt.Handle(mux, "/docs/users/{name}/", makeNodeHandler("other")); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := RegisterNodes(mux, rt, newTestSite(t), bind); err == nil {
+		t.Fatalf("error expected, but got %+v", report)
+	}
+	if _, pattern := mux.Handler(httptest.NewRequest("GET", "/docs/", nil)); pattern != "" {
+		t.Errorf("route %q registered, although a later node conflicts", pattern)
+	}
+}
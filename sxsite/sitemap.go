@@ -0,0 +1,149 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sxwebs/sxhtml"
+	"t73f.de/r/webs/site"
+)
+
+// Keys of the node metadata, that are used for the sitemap.
+const (
+	MetaLastMod    = "lastmod"    // date of the last change, e.g. "2026-10-18"
+	MetaChangeFreq = "changefreq" // e.g. "daily", "weekly"
+	MetaPriority   = "priority"   // "0.0" ... "1.0"
+)
+
+// changeFreqs are the valid values of MetaChangeFreq.
+var changeFreqs = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
+// Sitemap generates a sitemap.xml (https://www.sitemaps.org/) for all nodes
+// of a site. Nodes with path parameters and invisible nodes are not listed.
+// The optional values lastmod, changefreq, and priority of an entry are taken
+// from the metadata of the node.
+type Sitemap struct {
+	site   *site.Site
+	origin string
+}
+
+// NewSitemap creates a new sitemap for the site. Since a sitemap must
+// contain absolute URLs, the origin (e.g. "https://example.com") is placed
+// before the URLs of all nodes.
+func NewSitemap(st *site.Site, origin string) *Sitemap {
+	return &Sitemap{site: st, origin: strings.TrimSuffix(origin, "/")}
+}
+
+// SxXML returns the sitemap as SxHTML, to be rendered by a generator in XML
+// mode. Invalid metadata of a node results in an error.
+func (sm *Sitemap) SxXML() (*sx.Pair, error) {
+	var lb sx.ListBuilder
+	lb.Add(sxhtml.MakeSymbol("urlset"))
+	lb.Add(sx.MakeList(sx.Cons(sxhtml.MakeSymbol("xmlns"), sx.MakeString("http://www.sitemaps.org/schemas/sitemap/0.9"))))
+	for node := range siteNodes(sm.site) {
+		if !node.IsVisible() || hasParams(node) {
+			continue
+		}
+		entry, err := sm.entry(node)
+		if err != nil {
+			return nil, fmt.Errorf("node %q: %w", node.ID(), err)
+		}
+		if entry != nil {
+			lb.Add(entry)
+		}
+	}
+	return sx.MakeList(sxhtml.SymDoctype, lb.List()), nil
+}
+
+func (sm *Sitemap) entry(node *site.Node) (*sx.Pair, error) {
+	u, found := nodeURL(sm.site, node.ID())
+	if !found {
+		return nil, nil
+	}
+	var lb sx.ListBuilder
+	lb.Add(sxhtml.MakeSymbol("url"))
+	lb.Add(sx.MakeList(sxhtml.MakeSymbol("loc"), sx.MakeString(sm.origin+u)))
+	if lastMod, found := nodeMeta(node, MetaLastMod); found {
+		if _, err := time.Parse(time.DateOnly, lastMod); err != nil {
+			if _, err = time.Parse(time.RFC3339, lastMod); err != nil {
+				return nil, fmt.Errorf("invalid %s %q", MetaLastMod, lastMod)
+			}
+		}
+		lb.Add(sx.MakeList(sxhtml.MakeSymbol("lastmod"), sx.MakeString(lastMod)))
+	}
+	if changeFreq, found := nodeMeta(node, MetaChangeFreq); found {
+		if !slices.Contains(changeFreqs, changeFreq) {
+			return nil, fmt.Errorf("invalid %s %q", MetaChangeFreq, changeFreq)
+		}
+		lb.Add(sx.MakeList(sxhtml.MakeSymbol("changefreq"), sx.MakeString(changeFreq)))
+	}
+	if priority, found := nodeMeta(node, MetaPriority); found {
+		if p, err := strconv.ParseFloat(priority, 64); err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("invalid %s %q", MetaPriority, priority)
+		}
+		lb.Add(sx.MakeList(sxhtml.MakeSymbol("priority"), sx.MakeString(priority)))
+	}
+	return lb.List(), nil
+}
+
+// WriteXML writes the sitemap as XML.
+func (sm *Sitemap) WriteXML(w io.Writer) error {
+	sitemap, err := sm.SxXML()
+	if err != nil {
+		return err
+	}
+	return sxhtml.NewGenerator().SetXML().WriteHTML(w, sitemap)
+}
+
+// ServeHTTP sends the sitemap.
+func (sm *Sitemap) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+	if err := sm.WriteXML(&buf); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// RobotsTxt returns a handler that sends a robots.txt, that points to the
+// sitemap at the given absolute URL. All paths of disallow are forbidden for
+// all user agents.
+func RobotsTxt(sitemapURL string, disallow ...string) http.Handler {
+	var sb strings.Builder
+	sb.WriteString("User-agent: *\n")
+	if len(disallow) == 0 {
+		sb.WriteString("Disallow:\n")
+	}
+	for _, p := range disallow {
+		sb.WriteString("Disallow: " + p + "\n")
+	}
+	if sitemapURL != "" {
+		sb.WriteString("\nSitemap: " + sitemapURL + "\n")
+	}
+	content := []byte(sb.String())
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(content)
+	})
+}
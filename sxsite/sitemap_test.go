@@ -0,0 +1,107 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSitemap(t *testing.T) {
+	t.Parallel()
+	st := newTestSite(t)
+	home, intro := st.Root(), st.GetNode("intro")
+	home.SetMeta(MetaLastMod, "2026-10-18")
+	home.SetMeta(MetaChangeFreq, "weekly")
+	home.SetMeta(MetaPriority, "1.0")
+	intro.SetMeta(MetaLastMod, "2026-10-18T12:00:00Z")
+	intro.SetMeta(MetaPriority, "0.0")
+	st.GetNode("users").SetMeta(MetaPriority, "0.25")
+	exp := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+		`<url><loc>https://example.com/docs/</loc><lastmod>2026-10-18</lastmod><changefreq>weekly</changefreq><priority>1.0</priority></url>` +
+		`<url><loc>https://example.com/docs/intro/</loc><lastmod>2026-10-18T12:00:00Z</lastmod><priority>0.0</priority></url>` +
+		`<url><loc>https://example.com/docs/users/</loc><priority>0.25</priority></url>` +
+		`</urlset>`
+	var sb strings.Builder
+	if err := NewSitemap(st, "https://example.com/").WriteXML(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); got != exp {
+		t.Errorf("expected\n%s\nbut got\n%s", exp, got)
+	}
+
+	rec := httptest.NewRecorder()
+	NewSitemap(st, "https://example.com").ServeHTTP(rec, httptest.NewRequest("GET", "/sitemap.xml", nil))
+	if got := rec.Body.String(); got != exp {
+		t.Errorf("expected\n%s\nbut got\n%s", exp, got)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+}
+
+func TestSitemapInvalidMeta(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		key string
+		val string
+	}{
+		{MetaLastMod, "yesterday"},
+		{MetaChangeFreq, "sometimes"},
+		{MetaPriority, "high"},
+		{MetaPriority, "1.5"},
+		{MetaPriority, "-0.1"},
+	}
+	for _, tc := range testcases {
+		st := newTestSite(t)
+		st.GetNode("intro").SetMeta(tc.key, tc.val)
+		if _, err := NewSitemap(st, "").SxXML(); err == nil {
+			t.Errorf("%s %q: error expected", tc.key, tc.val)
+		}
+		rec := httptest.NewRecorder()
+		NewSitemap(st, "").ServeHTTP(rec, httptest.NewRequest("GET", "/sitemap.xml", nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("%s %q: expected status 500, but got %d", tc.key, tc.val, rec.Code)
+		}
+	}
+}
+
+func TestRobotsTxt(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name     string
+		sitemap  string
+		disallow []string
+		exp      string
+	}{
+		{"empty", "", nil, "User-agent: *\nDisallow:\n"},
+		{"sitemap", "https://example.com/sitemap.xml", nil, "User-agent: *\nDisallow:\n\nSitemap: https://example.com/sitemap.xml\n"},
+		{"disallow", "", []string{"/admin/", "/tmp/"}, "User-agent: *\nDisallow: /admin/\nDisallow: /tmp/\n"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RobotsTxt(tc.sitemap, tc.disallow...).ServeHTTP(rec, httptest.NewRequest("GET", "/robots.txt", nil))
+			if got := rec.Body.String(); got != tc.exp {
+				t.Errorf("expected %q, but got %q", tc.exp, got)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+				t.Errorf("unexpected content type %q", ct)
+			}
+		})
+	}
+}
@@ -21,11 +21,16 @@ import (
 	"t73f.de/r/sx/sxbuiltins"
 	"t73f.de/r/sx/sxeval"
 	"t73f.de/r/webs/site"
-	"t73f.de/r/webs/urlbuilder"
 )
 
 // MakeURLForBuiltin returns a builtin that provides the (url-for node-id args...)
 // function. It is specific to a webs/site.Site.
+//
+// Path values for the node are strings, numbers, or symbols. They may be
+// followed by query parameters, as association lists or keyword arguments
+// (:key value), and by a fragment string that starts with "#":
+//
+//	(url-for "user" 42 '((tab . "posts")) "#top")
 func MakeURLForBuiltin(st *site.Site) *sxeval.Builtin {
 	return &sxeval.Builtin{
 		Name:     "url-for",
@@ -33,38 +38,39 @@ func MakeURLForBuiltin(st *site.Site) *sxeval.Builtin {
 		MaxArity: -1,
 		TestPure: sxeval.AssertPure,
 		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
-			nodeID, errArg := sxbuiltins.GetString(arg, 0)
-			if errArg != nil {
-				return nil, errArg
-			}
-			return builderToSx(nodeID, st.BuilderFor(nodeID.GetValue()))
+			return urlFor(st, sx.Vector{arg}, 0)
 		},
 		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
-			nodeID, err := sxbuiltins.GetString(args[0], 0)
-			if err != nil {
-				return nil, err
-			}
-			vals := make([]any, 0, len(args)-1)
-			for i := 1; i < len(args); i++ {
-				val, errArg := sxbuiltins.GetString(args[i], i) // TODO: more than just string?
-				if errArg != nil {
-					return nil, errArg
-				}
-				vals = append(vals, val.GetValue())
-			}
-			return builderToSx(nodeID, st.BuilderFor(nodeID.GetValue(), vals...))
+			return urlFor(st, args, 0)
 		},
 	}
 }
-func builderToSx(nodeID sx.Object, ub *urlbuilder.URLBuilder) (sx.Object, error) {
-	if ub == nil {
+
+// urlFor returns the URL of the node, whose id is at the given position of
+// the arguments, followed by the URL arguments.
+func urlFor(st *site.Site, args sx.Vector, pos int) (sx.Object, error) {
+	nodeID, err := sxbuiltins.GetString(args[pos], pos)
+	if err != nil {
+		return nil, err
+	}
+	ua, err := parseURLArgs(args, pos+1)
+	if err != nil {
+		return nil, err
+	}
+	u, found := nodeURL(st, nodeID.GetValue(), ua.path...)
+	if !found {
 		return nil, fmt.Errorf("node id not found: %v", nodeID)
 	}
-	return sx.MakeString(ub.String()), nil
+	return sx.MakeString(ua.apply(u)), nil
 }
 
 // MakeMakeURLBuiltin returns a builtin that provides the (make-url path...)
 // function. It is specific to a webs/site.Site.
+//
+// As with "url-for", the path elements may be followed by query parameters
+// and a fragment. Repeated keys are allowed, all values are percent-encoded:
+//
+//	(make-url "search" :q "sx lisp" :page 2 "#results")
 func MakeMakeURLBuiltin(st *site.Site) *sxeval.Builtin {
 	return &sxeval.Builtin{
 		Name:     "make-url",
@@ -72,27 +78,21 @@ func MakeMakeURLBuiltin(st *site.Site) *sxeval.Builtin {
 		MaxArity: -1,
 		TestPure: sxeval.AssertPure,
 		Fn0: func(*sxeval.Environment, *sxeval.Frame) (sx.Object, error) {
-			return sx.MakeString(st.MakeURLBuilder().String()), nil
+			return sx.MakeString(pathURL(st)), nil
 		},
 		Fn1: func(_ *sxeval.Environment, arg sx.Object, _ *sxeval.Frame) (sx.Object, error) {
-			s, err := sxbuiltins.GetString(arg, 0)
-			if err != nil {
-				return nil, err
-			}
-			ub := st.MakeURLBuilder()
-			ub = ub.AddPath(s.GetValue())
-			return sx.MakeString(ub.String()), nil
+			return makeURL(st, sx.Vector{arg})
 		},
 		Fn: func(_ *sxeval.Environment, args sx.Vector, _ *sxeval.Frame) (sx.Object, error) {
-			ub := st.MakeURLBuilder()
-			for i, arg := range args {
-				sVal, err := sxbuiltins.GetString(arg, i)
-				if err != nil {
-					return nil, err
-				}
-				ub = ub.AddPath(sVal.GetValue())
-			}
-			return sx.MakeString(ub.String()), nil
+			return makeURL(st, args)
 		},
 	}
 }
+
+func makeURL(st *site.Site, args sx.Vector) (sx.Object, error) {
+	ua, err := parseURLArgs(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sx.MakeString(ua.apply(pathURL(st, ua.path...))), nil
+}
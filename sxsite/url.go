@@ -0,0 +1,159 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"t73f.de/r/sx"
+)
+
+// urlArgs are the parsed arguments of an URL builtin, like "url-for" or
+// "make-url".
+//
+// Path values are strings, numbers, or symbols. They are followed by query
+// parameters, given as association lists or as keyword arguments
+// (:key value), and an optional fragment. The fragment is the last argument,
+// if it is a string that starts with "#". Therefore, a path value that starts
+// with "#" can only be the last argument, if it is followed by the empty
+// fragment "#".
+type urlArgs struct {
+	path        []string
+	query       [][2]string
+	fragment    string
+	hasFragment bool
+}
+
+// parseURLArgs parses the arguments, starting at the given position.
+func parseURLArgs(args sx.Vector, start int) (urlArgs, error) {
+	var ua urlArgs
+	inQuery := false
+	for i := start; i < len(args); i++ {
+		arg := args[i]
+		if fragment, isFragment := getFragment(arg); isFragment && i == len(args)-1 {
+			ua.fragment, ua.hasFragment = fragment, fragment != ""
+			continue
+		}
+		if sym, isSymbol := sx.GetSymbol(arg); isSymbol && len(sym.GetValue()) > 1 && strings.HasPrefix(sym.GetValue(), ":") {
+			if i+1 >= len(args) {
+				return ua, fmt.Errorf("argument %d: keyword %v without value", i+1, sym)
+			}
+			val, err := getURLValue(args[i+1], i+1)
+			if err != nil {
+				return ua, err
+			}
+			ua.query = append(ua.query, [2]string{sym.GetValue()[1:], val})
+			inQuery = true
+			i++
+			continue
+		}
+		if alist, isPair := sx.GetPair(arg); isPair {
+			if err := ua.addAlist(alist, i); err != nil {
+				return ua, err
+			}
+			inQuery = true
+			continue
+		}
+		if inQuery {
+			return ua, fmt.Errorf("argument %d: path value %v after query parameters", i+1, arg)
+		}
+		val, err := getURLValue(arg, i)
+		if err != nil {
+			return ua, err
+		}
+		ua.path = append(ua.path, val)
+	}
+	return ua, nil
+}
+
+// getFragment returns the fragment of a string that starts with "#". Only
+// the last argument of an URL builtin may be a fragment.
+func getFragment(obj sx.Object) (string, bool) {
+	if s, isString := sx.GetString(obj); isString {
+		return strings.CutPrefix(s.GetValue(), "#")
+	}
+	return "", false
+}
+
+// addAlist adds the query parameters of an association list. If the value
+// of an entry is a list, the key is repeated for every element.
+func (ua *urlArgs) addAlist(alist *sx.Pair, pos int) error {
+	for elem := range alist.Values() {
+		pair, isPair := sx.GetPair(elem)
+		if !isPair || pair == nil {
+			return fmt.Errorf("argument %d is not an association list, but %T/%v", pos+1, alist, alist)
+		}
+		key, err := getURLValue(pair.Car(), pos)
+		if err != nil {
+			return err
+		}
+		if vals, isList := sx.GetPair(pair.Cdr()); isList {
+			for v := range vals.Values() {
+				val, errVal := getURLValue(v, pos)
+				if errVal != nil {
+					return errVal
+				}
+				ua.query = append(ua.query, [2]string{key, val})
+			}
+			continue
+		}
+		val, err := getURLValue(pair.Cdr(), pos)
+		if err != nil {
+			return err
+		}
+		ua.query = append(ua.query, [2]string{key, val})
+	}
+	return nil
+}
+
+// getURLValue returns the string value of a string, number, or symbol.
+func getURLValue(obj sx.Object, pos int) (string, error) {
+	switch o := obj.(type) {
+	case sx.String:
+		return o.GetValue(), nil
+	case *sx.Symbol:
+		return o.GetValue(), nil
+	case sx.Number:
+		return o.String(), nil
+	}
+	return "", fmt.Errorf("argument %d is not a string, number, or symbol, but %T/%v", pos+1, obj, obj)
+}
+
+// apply adds the percent-encoded query parameters and the fragment to the
+// given URL.
+func (ua *urlArgs) apply(base string) string {
+	if len(ua.query) == 0 && !ua.hasFragment {
+		return base
+	}
+	var sb strings.Builder
+	sb.WriteString(base)
+	sep := byte('?')
+	if strings.IndexByte(base, '?') >= 0 {
+		sep = '&'
+	}
+	for _, kv := range ua.query {
+		sb.WriteByte(sep)
+		sep = '&'
+		sb.WriteString(url.QueryEscape(kv[0]))
+		sb.WriteByte('=')
+		sb.WriteString(url.QueryEscape(kv[1]))
+	}
+	if ua.hasFragment {
+		sb.WriteByte('#')
+		sb.WriteString((&url.URL{Fragment: ua.fragment}).EscapedFragment())
+	}
+	return sb.String()
+}
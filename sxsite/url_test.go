@@ -0,0 +1,198 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2026-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2026-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sxsite
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/sx"
+	"t73f.de/r/sx/sxeval"
+	"t73f.de/r/sx/sxreader"
+)
+
+func TestURLArgs(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		src  string
+		path []string
+		url  string
+	}{
+		{"empty", `()`, nil, "/base"},
+		{"path", `("a" 42 b)`, []string{"a", "42", "b"}, "/base"},
+		{"alist", `(42 ((tab . "posts") (page . 2)))`, []string{"42"}, "/base?tab=posts&page=2"},
+		{"repeated", `(((tag "a b" "c&d")))`, nil, "/base?tag=a+b&tag=c%26d"},
+		{"keywords", `(17 :tab "posts" :sort name)`, []string{"17"}, "/base?tab=posts&sort=name"},
+		{"fragment", `("user" "#top")`, []string{"user"}, "/base#top"},
+		{"all", `(42 ((tab . "posts")) "#a b")`, []string{"42"}, "/base?tab=posts#a%20b"},
+		{"search", `("search" :q "sx lisp" :page 2 "#results")`, []string{"search"}, "/base?q=sx+lisp&page=2#results"},
+		{"hash-path", `("#tag" "x")`, []string{"#tag", "x"}, "/base"},
+		{"hash-last", `("x" "#tag" "#")`, []string{"x", "#tag"}, "/base"},
+		{"empty-fragment", `("x" "#")`, []string{"x"}, "/base"},
+		{"hash-value", `(:q "#x")`, nil, "/base?q=%23x"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ua, err := parseURLArgs(mustReadVector(t, tc.src), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ua.path, tc.path) {
+				t.Errorf("expected path %q, but got %q", tc.path, ua.path)
+			}
+			if got := ua.apply("/base"); got != tc.url {
+				t.Errorf("expected URL %q, but got %q", tc.url, got)
+			}
+		})
+	}
+}
+
+func TestURLArgsError(t *testing.T) {
+	t.Parallel()
+	for _, src := range []string{
+		`(:q "a" "b")`,
+		`(:tab)`,
+		`(((tab . "posts")) "a")`,
+		`((1 2))`,
+	} {
+		if _, err := parseURLArgs(mustReadVector(t, src), 0); err == nil {
+			t.Errorf("error expected for %s", src)
+		}
+	}
+}
+
+func TestURLArgsApply(t *testing.T) {
+	t.Parallel()
+	ua := urlArgs{query: [][2]string{{"b", "2"}}}
+	if got := ua.apply("/x?a=1"); got != "/x?a=1&b=2" {
+		t.Errorf("unexpected URL %q", got)
+	}
+}
+
+// testSource is a site with the base "/base/", and with the nodes "home"
+// and "user", whose URLs are "/base/" and "/base/user/{id}/".
+type testSource struct{}
+
+func (testSource) nodeURL(nodeID string, vals ...string) (string, bool) {
+	switch {
+	case nodeID == "home" && len(vals) == 0:
+		return "/base/", true
+	case nodeID == "user" && len(vals) == 1:
+		return "/base/user/" + url.PathEscape(vals[0]) + "/", true
+	}
+	return "", false
+}
+
+func (testSource) pathURL(elems ...string) string {
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return "/base/" + strings.Join(elems, "/")
+}
+
+func TestURLBuiltins(t *testing.T) {
+	t.Parallel()
+	urlFor, makeURL := makeURLForBuiltin(testSource{}), makeMakeURLBuiltin(testSource{})
+	testcases := []struct {
+		name    string
+		builtin *sxeval.Builtin
+		src     string
+		exp     string
+	}{
+		{"url-for-home", urlFor, `("home")`, "/base/"},
+		{"url-for-user", urlFor, `("user" 42)`, "/base/user/42/"},
+		{"url-for-all", urlFor, `("user" 42 :tab "posts" "#top")`, "/base/user/42/?tab=posts#top"},
+		{"url-for-hash", urlFor, `("user" "#42" "#")`, "/base/user/%2342/"},
+		{"url-for-missing", urlFor, `("user")`, ""},
+		{"url-for-unknown", urlFor, `("none")`, ""},
+		{"url-for-no-id", urlFor, `(42)`, ""},
+		{"make-url", makeURL, `()`, "/base/"},
+		{"make-url-one", makeURL, `("a")`, "/base/a"},
+		{"make-url-all", makeURL, `("a" "b c" :q 1 "#f")`, "/base/a/b%20c?q=1#f"},
+		{"make-url-error", makeURL, `(:q)`, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := mustReadVector(t, tc.src)
+			var got sx.Object
+			var err error
+			switch len(args) {
+			case 0:
+				got, err = tc.builtin.Fn0(nil, nil)
+			case 1:
+				got, err = tc.builtin.Fn1(nil, args[0], nil)
+			default:
+				got, err = tc.builtin.Fn(nil, args, nil)
+			}
+			if tc.exp == "" {
+				if err == nil {
+					t.Errorf("error expected, but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s, _ := sx.GetString(got); s.GetValue() != tc.exp {
+				t.Errorf("expected %q, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestMakeURLQuery(t *testing.T) {
+	t.Parallel()
+	makeURL := makeMakeURLBuiltin(testSource{})
+	testcases := []struct {
+		src string
+		exp string
+	}{
+		{`("list" :page 2 :sort name)`, "/base/list?page=2&sort=name"},
+		{`("list" :tag "a" :tag "b")`, "/base/list?tag=a&tag=b"},
+		{`("list" ((tag "a" "b") (page . 2)))`, "/base/list?tag=a&tag=b&page=2"},
+		{`("list" :q "a&b=c d")`, "/base/list?q=a%26b%3Dc+d"},
+		{`("list" ((":k" . "?")))`, "/base/list?%3Ak=%3F"},
+		{`("doc" "#section 1")`, "/base/doc#section%201"},
+		{`("doc" :q "x" "#a#b")`, "/base/doc?q=x#a%23b"},
+		{`("#doc" "#")`, "/base/%23doc"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.src, func(t *testing.T) {
+			got, err := makeURL.Fn(nil, mustReadVector(t, tc.src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s, _ := sx.GetString(got); s.GetValue() != tc.exp {
+				t.Errorf("expected %q, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func mustReadVector(t *testing.T, src string) sx.Vector {
+	t.Helper()
+	obj, err := sxreader.MakeReader(strings.NewReader(src)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lst, _ := sx.GetPair(obj)
+	var result sx.Vector
+	for elem := range lst.Values() {
+		result = append(result, elem)
+	}
+	return result
+}